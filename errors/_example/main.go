@@ -11,7 +11,7 @@ import (
 
 var (
 	FileAccessError = errors.Define("file i/o error")
-	ValidationError = errors.Define("file i/o error")
+	ValidationError = errors.Define("validation error")
 	NetworkError    = errors.Define("network i/o error")
 )
 
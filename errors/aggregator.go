@@ -0,0 +1,130 @@
+package errors
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gotech-labs/core/log"
+	"github.com/gotech-labs/core/system"
+)
+
+// Group is the summary of the occurrences of the errors sharing a
+// fingerprint.
+type Group struct {
+	Fingerprint string
+	// Err is the first occurrence.
+	Err       error
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Aggregator counts the occurrences of the errors per fingerprint, so that
+// one summary line can be logged per window instead of every duplicate.
+type Aggregator struct {
+	window time.Duration
+	clock  system.Clock
+	mu     sync.Mutex
+	groups map[string]*Group
+}
+
+type aggregatorOption func(*Aggregator)
+
+// WithAggregatorClock replaces the clock of the aggregator, for tests.
+func WithAggregatorClock(clock system.Clock) aggregatorOption {
+	return func(a *Aggregator) {
+		a.clock = clock
+	}
+}
+
+func NewAggregator(window time.Duration, options ...aggregatorOption) *Aggregator {
+	a := &Aggregator{
+		window: window,
+		clock:  system.DefaultClock,
+		groups: make(map[string]*Group),
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Add records an occurrence of err. It reports whether it is the first
+// occurrence of its fingerprint in the current window, which callers
+// usually log in full.
+func (a *Aggregator) Add(err error) bool {
+	if err == nil {
+		return false
+	}
+	fingerprint := Fingerprint(err)
+	now := a.clock.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	group, ok := a.groups[fingerprint]
+	if !ok {
+		a.groups[fingerprint] = &Group{
+			Fingerprint: fingerprint,
+			Err:         err,
+			Count:       1,
+			FirstSeen:   now,
+			LastSeen:    now,
+		}
+		return true
+	}
+	group.Count++
+	group.LastSeen = now
+	return false
+}
+
+// Flush returns the groups of the current window by descending count and
+// starts a new window.
+func (a *Aggregator) Flush() []Group {
+	a.mu.Lock()
+	groups := a.groups
+	a.groups = make(map[string]*Group, len(groups))
+	a.mu.Unlock()
+
+	flushed := make([]Group, 0, len(groups))
+	for _, group := range groups {
+		flushed = append(flushed, *group)
+	}
+	slices.SortFunc(flushed, func(a, b Group) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return a.FirstSeen.Compare(b.FirstSeen)
+	})
+	return flushed
+}
+
+// Run logs a summary line per group at the end of every window, until ctx
+// is done.
+func (a *Aggregator) Run(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			a.logSummary(context.WithoutCancel(ctx))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			a.logSummary(context.WithoutCancel(ctx))
+			return
+		case <-a.clock.After(a.window):
+			a.logSummary(ctx)
+		}
+	}
+}
+
+func (a *Aggregator) logSummary(ctx context.Context) {
+	for _, group := range a.Flush() {
+		log.WarnWithContext(ctx, "error summary",
+			"fingerprint", group.Fingerprint,
+			"count", group.Count,
+			"first_seen", group.FirstSeen,
+			"last_seen", group.LastSeen,
+			"error", group.Err)
+	}
+}
@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"log/slog"
+)
+
+const badKey = "!BADKEY"
+
+// isAttrArgs reports whether args consist only of key/value pairs and
+// slog.Attr values.
+func isAttrArgs(args []any) bool {
+	for len(args) > 0 {
+		switch args[0].(type) {
+		case string:
+			if len(args) == 1 {
+				return false
+			}
+			args = args[2:]
+		case slog.Attr:
+			args = args[1:]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// OwnAttrs returns the attributes given to the outermost error of err,
+// leaving out those of its causes and those captured from a context.
+func OwnAttrs(err error) []slog.Attr {
+	if e, ok := AsError(err).(*coreError); ok {
+		return e.attrs
+	}
+	return nil
+}
+
+func argsToAttrs(args []any) []slog.Attr {
+	if len(args) == 0 {
+		return nil
+	}
+	attrs := make([]slog.Attr, 0, len(args)/2+1)
+	for len(args) > 0 {
+		switch x := args[0].(type) {
+		case string:
+			if len(args) == 1 {
+				attrs = append(attrs, slog.String(badKey, x))
+				args = nil
+				continue
+			}
+			attrs = append(attrs, slog.Any(x, args[1]))
+			args = args[2:]
+		case slog.Attr:
+			attrs = append(attrs, x)
+			args = args[1:]
+		default:
+			attrs = append(attrs, slog.Any(badKey, x))
+			args = args[1:]
+		}
+	}
+	return attrs
+}
+
+// mergeAttrs returns base overridden by the attributes of overrides.
+// The order of first appearance is kept.
+func mergeAttrs(base []slog.Attr, overrides []slog.Attr) []slog.Attr {
+	if len(base) == 0 {
+		return overrides
+	}
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := make([]slog.Attr, len(base), len(base)+len(overrides))
+	copy(merged, base)
+	for _, attr := range overrides {
+		replaced := false
+		for i := range merged {
+			if merged[i].Key == attr.Key {
+				merged[i] = attr
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, attr)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,267 @@
+// Package catalog localizes the messages of the core errors.
+//
+// Message catalogs are YAML or JSON files named after their language, like
+// "ja.yaml" or "errors.en.json", mapping message keys to templates. Nested
+// maps are flattened with dots, so that the keys match the namespaced error
+// codes. Templates reference the error attributes as "{name}", the error
+// message as "{message}" and nested attributes as "{group.name}".
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gotech-labs/core/errors"
+)
+
+// MissingKey reports a message key without translation.
+type MissingKey struct {
+	Lang string
+	Key  string
+}
+
+type Catalog struct {
+	defaultLang string
+	mu          sync.RWMutex
+	messages    map[string]map[string]string
+	missingMu   sync.Mutex
+	missing     map[MissingKey]struct{}
+}
+
+func New(defaultLang string) *Catalog {
+	return &Catalog{
+		defaultLang: normalize(defaultLang),
+		messages:    make(map[string]map[string]string),
+		missing:     make(map[MissingKey]struct{}),
+	}
+}
+
+// Add adds the message templates of a language.
+func (c *Catalog) Add(lang string, messages map[string]string) {
+	lang = normalize(lang)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]string, len(messages))
+	}
+	for key, message := range messages {
+		c.messages[lang][key] = message
+	}
+}
+
+// Load loads the catalog files of fsys matching the patterns.
+func (c *Catalog) Load(fsys fs.FS, patterns ...string) error {
+	for _, pattern := range patterns {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := c.loadFile(fsys, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) loadFile(fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	ext := path.Ext(name)
+	var values map[string]any
+	switch ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("catalog: unsupported file %s", name)
+	}
+	if err != nil {
+		return fmt.Errorf("catalog: %s: %w", name, err)
+	}
+	base := strings.TrimSuffix(path.Base(name), ext)
+	lang := base[strings.LastIndex(base, ".")+1:]
+	messages := make(map[string]string)
+	flatten("", values, messages)
+	c.Add(lang, messages)
+	return nil
+}
+
+func flatten(prefix string, values map[string]any, messages map[string]string) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flatten(key, nested, messages)
+			continue
+		}
+		messages[key] = fmt.Sprint(value)
+	}
+}
+
+// Localize returns the message of err in the preferred language. lang is
+// a language tag like "ja" or an Accept-Language header value like
+// "ja-JP,ja;q=0.9,en;q=0.8". The languages are tried in order of
+// preference, then without region, then the default language. The error
+// message is returned when there is no translation at all.
+func (c *Catalog) Localize(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+	e := errors.AsError(err)
+	if e == nil {
+		return err.Error()
+	}
+	definition, ok := errors.Lookup(e.Code())
+	if !ok {
+		return e.Message()
+	}
+	langs := c.fallbacks(lang)
+	if template, ok := c.lookup(langs, definition.MessageKey); ok {
+		return expand(template, params(e))
+	}
+	if len(langs) > 0 {
+		c.missingMu.Lock()
+		c.missing[MissingKey{Lang: langs[0], Key: definition.MessageKey}] = struct{}{}
+		c.missingMu.Unlock()
+	}
+	if definition.Status.PublicMessage != "" {
+		return definition.Status.PublicMessage
+	}
+	return e.Message()
+}
+
+func (c *Catalog) lookup(langs []string, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, lang := range langs {
+		if template, ok := c.messages[lang][key]; ok {
+			return template, true
+		}
+	}
+	return "", false
+}
+
+// fallbacks returns the languages to try for lang.
+func (c *Catalog) fallbacks(lang string) []string {
+	var langs []string
+	add := func(l string) {
+		if l != "" && l != "*" && !slices.Contains(langs, l) {
+			langs = append(langs, l)
+		}
+	}
+	for _, tag := range ParseAcceptLanguage(lang) {
+		add(tag)
+		if pos := strings.Index(tag, "-"); pos > 0 {
+			add(tag[:pos])
+		}
+	}
+	add(c.defaultLang)
+	return langs
+}
+
+// Missing returns the keys which were looked up without translation.
+func (c *Catalog) Missing() []MissingKey {
+	c.missingMu.Lock()
+	defer c.missingMu.Unlock()
+	missing := make([]MissingKey, 0, len(c.missing))
+	for key := range c.missing {
+		missing = append(missing, key)
+	}
+	sortMissing(missing)
+	return missing
+}
+
+// Check returns the message keys of the defined error types without
+// translation in the given languages, or in the default language if none.
+func (c *Catalog) Check(langs ...string) []MissingKey {
+	if len(langs) == 0 {
+		langs = []string{c.defaultLang}
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var missing []MissingKey
+	for _, definition := range errors.Types() {
+		for _, lang := range langs {
+			lang = normalize(lang)
+			if _, ok := c.messages[lang][definition.MessageKey]; !ok {
+				missing = append(missing, MissingKey{Lang: lang, Key: definition.MessageKey})
+			}
+		}
+	}
+	sortMissing(missing)
+	return missing
+}
+
+func sortMissing(missing []MissingKey) {
+	slices.SortFunc(missing, func(a, b MissingKey) int {
+		if c := strings.Compare(a.Lang, b.Lang); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+}
+
+func params(e errors.Error) map[string]string {
+	params := map[string]string{
+		"message": e.Message(),
+		"type":    string(e.Type()),
+		"code":    string(e.Code()),
+	}
+	addParams("", e.Attrs(), params)
+	return params
+}
+
+func addParams(prefix string, attrs []slog.Attr, params map[string]string) {
+	for _, attr := range attrs {
+		value := attr.Value.Resolve()
+		if value.Kind() == slog.KindGroup {
+			addParams(prefix+attr.Key+".", value.Group(), params)
+			continue
+		}
+		params[prefix+attr.Key] = value.String()
+	}
+}
+
+// expand replaces the "{name}" placeholders of template. Unknown
+// placeholders are kept as is.
+func expand(template string, params map[string]string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(template[:start])
+		if value, ok := params[template[start+1:end]]; ok {
+			b.WriteString(value)
+		} else {
+			b.WriteString(template[start : end+1])
+		}
+		template = template[end+1:]
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+func normalize(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
@@ -0,0 +1,112 @@
+package catalog_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gotech-labs/core/errors"
+	. "github.com/gotech-labs/core/errors/catalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalize(t *testing.T) {
+	OutOfStockError := errors.Namespace("shop").Define("out of stock",
+		errors.WithPublicMessage("the item is not available"))
+	c := New("en")
+	require.NoError(t, c.Load(fstest.MapFS{
+		"messages.en.yaml": {Data: []byte("shop:\n  out_of_stock: \"{item} is out of stock ({stock.left} left)\"\n")},
+		"messages.ja.json": {Data: []byte(`{"shop": {"out_of_stock": "{item}の在庫がありません"}}`)},
+	}, "*.yaml", "*.json"))
+	err := OutOfStockError.New("no stock", "item", "apple")
+	for _, in := range []struct {
+		name     string
+		err      error
+		lang     string
+		expected string
+	}{
+		{
+			name:     "test - nil error",
+			err:      nil,
+			lang:     "ja",
+			expected: "",
+		},
+		{
+			name:     "test - language tag",
+			err:      err,
+			lang:     "ja",
+			expected: "appleの在庫がありません",
+		},
+		{
+			name:     "test - accept-language header",
+			err:      err,
+			lang:     "fr-CH, ja-JP;q=0.9, en;q=0.8",
+			expected: "appleの在庫がありません",
+		},
+		{
+			name:     "test - default language fallback",
+			err:      OutOfStockError.New("no stock", "item", "pear"),
+			lang:     "fr",
+			expected: "pear is out of stock ({stock.left} left)",
+		},
+		{
+			name:     "test - wrapped error",
+			err:      errors.UnexpectedError.Wrap(err, "checkout failed"),
+			lang:     "en",
+			expected: "checkout failed",
+		},
+		{
+			name:     "test - no language",
+			err:      err,
+			lang:     "",
+			expected: "apple is out of stock ({stock.left} left)",
+		},
+		{
+			name:     "test - standard error",
+			err:      context.Canceled,
+			lang:     "ja",
+			expected: "context canceled",
+		},
+	} {
+		t.Run(in.name, func(t *testing.T) {
+			assert.Equal(t, in.expected, c.Localize(in.err, in.lang))
+		})
+	}
+
+	empty := New("en")
+	assert.Equal(t, "the item is not available", empty.Localize(err, "ja"))
+	assert.Equal(t, "no stock", empty.Localize(errors.InitializationError.New("no stock"), "ja"))
+	assert.Equal(t, []MissingKey{
+		{Lang: "ja", Key: "core.initialization_error"},
+		{Lang: "ja", Key: "shop.out_of_stock"},
+	}, empty.Missing())
+
+	noLang := New("")
+	assert.Equal(t, "x", noLang.Localize(errors.ValidationError.New("x"), ""))
+	assert.Empty(t, noLang.Missing())
+}
+
+func TestLocalizeContext(t *testing.T) {
+	ctx := WithLanguage(context.Background(), "ja-JP")
+	assert.Equal(t, "ja-JP", LanguageFrom(ctx))
+	assert.Equal(t, "認証が必要です。", LocalizeContext(ctx, errors.AuthenticationRequiredError.New("no token")))
+	assert.Equal(t, "Invalid parameter: name is empty",
+		Localize(errors.ValidationError.New("name is empty"), "en-US"))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"ja-jp", "ja", "en"}, ParseAcceptLanguage("en;q=0.5, ja-JP, ja;q=0.8, fr;q=0"))
+	assert.Empty(t, ParseAcceptLanguage(""))
+}
+
+func TestCheck(t *testing.T) {
+	for _, definition := range errors.Types() {
+		if definition.Package != "errors" {
+			continue
+		}
+		for _, lang := range []string{"en", "ja"} {
+			assert.NotContains(t, Default().Check(lang), MissingKey{Lang: lang, Key: definition.MessageKey})
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package catalog
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+)
+
+//go:embed messages
+var messages embed.FS
+
+var defaultCatalog = newDefault()
+
+func newDefault() *Catalog {
+	c := New("en")
+	if err := c.Load(messages, "messages/*.yaml"); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Default returns the default catalog, which translates the core error
+// types in English and Japanese.
+func Default() *Catalog {
+	return defaultCatalog
+}
+
+// Load loads catalog files into the default catalog.
+func Load(fsys fs.FS, patterns ...string) error {
+	return defaultCatalog.Load(fsys, patterns...)
+}
+
+// Localize localizes err with the default catalog.
+func Localize(err error, lang string) string {
+	return defaultCatalog.Localize(err, lang)
+}
+
+// LocalizeContext localizes err with the default catalog in the preferred
+// language carried by ctx.
+func LocalizeContext(ctx context.Context, err error) string {
+	return defaultCatalog.LocalizeContext(ctx, err)
+}
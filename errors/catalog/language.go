@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type languageKey struct{}
+
+// WithLanguage returns a copy of ctx carrying the preferred language, which
+// may be an Accept-Language header value.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// LanguageFrom returns the preferred language carried by ctx.
+func LanguageFrom(ctx context.Context) string {
+	lang, _ := ctx.Value(languageKey{}).(string)
+	return lang
+}
+
+// LocalizeContext localizes err in the preferred language carried by ctx.
+func (c *Catalog) LocalizeContext(ctx context.Context, err error) string {
+	return c.Localize(err, LanguageFrom(ctx))
+}
+
+// ParseAcceptLanguage returns the normalized language tags of an
+// Accept-Language header value by descending quality.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = normalize(tag)
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = v
+			}
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag, quality})
+		}
+	}
+	slices.SortStableFunc(tags, func(a, b weighted) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		}
+		return 0
+	})
+	langs := make([]string, len(tags))
+	for i, tag := range tags {
+		langs[i] = tag.tag
+	}
+	return langs
+}
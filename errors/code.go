@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Code is the stable machine-readable identity of an error type, like
+// "E1001" or "auth.required", which is independent of the display name.
+type Code string
+
+// Namespace prefixes the codes of the error types it defines, so that the
+// services sharing this library don't collide.
+type Namespace string
+
+// coreNamespace is the namespace of the error types defined by this package.
+const coreNamespace Namespace = "core"
+
+// Define defines a new error type whose code is prefixed by the namespace,
+// like "auth.required" for the "required" code of the "auth" namespace.
+func (ns Namespace) Define(name string, options ...option) ErrorFactory {
+	return define(ns, name, options)
+}
+
+func (ns Namespace) code(code Code) Code {
+	if ns == "" {
+		return code
+	}
+	return Code(string(ns) + "." + string(code))
+}
+
+// WithCode sets the code of the error type, which defaults to the
+// snake-cased name.
+func WithCode(code string) option {
+	return func(factory *errorFactory) {
+		factory.code = Code(code)
+	}
+}
+
+// WithMessageKey sets the key of the localized message of the error type,
+// which defaults to the code. See the catalog package.
+func WithMessageKey(key string) option {
+	return func(factory *errorFactory) {
+		factory.messageKey = key
+	}
+}
+
+func defaultCode(name string) Code {
+	code := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return Code(strings.Join(code, "_"))
+}
@@ -0,0 +1,19 @@
+package errors
+
+import (
+	"context"
+
+	"github.com/gotech-labs/core/internal/ctxattr"
+)
+
+func (ef *errorFactory) NewContext(ctx context.Context, format string, args ...any) Error {
+	e := newError(ef, nil, format, args...)
+	e.ctxAttrs = ctxattr.Attrs(ctx)
+	return e
+}
+
+func (ef *errorFactory) WrapContext(ctx context.Context, cause error, format string, args ...any) Error {
+	e := newError(ef, cause, format, args...)
+	e.ctxAttrs = ctxattr.Attrs(ctx)
+	return e
+}
@@ -0,0 +1,259 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// RemoteError is the type of the decoded errors whose type is not defined
+// in this process. Their Type still reports the original type name.
+var RemoteError = coreNamespace.Define("remote error")
+
+// wireError is the serialized form of an error and of its causes.
+type wireError struct {
+	Type  Type        `json:"type,omitempty"`
+	Code  Code        `json:"code,omitempty"`
+	Msg   string      `json:"msg"`
+	Attrs wireAttrs   `json:"attrs,omitempty"`
+	Ctx   wireAttrs   `json:"ctx,omitempty"`
+	Field string      `json:"field,omitempty"`
+	Cause *wireError  `json:"cause,omitempty"`
+	Stack []wireFrame `json:"stack,omitempty"`
+}
+
+type wireFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+type encodeSettings struct {
+	withStack bool
+}
+
+type encodeOption func(*encodeSettings)
+
+// WithStack selects whether the stack frames are serialized, they are by default.
+func WithStack(withStack bool) encodeOption {
+	return func(settings *encodeSettings) {
+		settings.withStack = withStack
+	}
+}
+
+// Marshal serializes err and its chain of causes so that it can be
+// rebuilt by Unmarshal in another process.
+func Marshal(err error, options ...encodeOption) ([]byte, error) {
+	settings := &encodeSettings{withStack: true}
+	for _, option := range options {
+		option(settings)
+	}
+	return json.Marshal(toWire(err, settings))
+}
+
+// Unmarshal rebuilds an error serialized by Marshal. The type of the error
+// is resolved from the locally defined types, falling back to RemoteError.
+func Unmarshal(data []byte) (Error, error) {
+	e := &coreError{}
+	if err := e.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *coreError) MarshalJSON() ([]byte, error) {
+	return Marshal(e)
+}
+
+func (e *coreError) UnmarshalJSON(data []byte) error {
+	var w wireError
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	if w.Type == "" {
+		remote := RemoteError.(*errorFactory)
+		w.Type, w.Code = remote.name, remote.code
+	}
+	*e = *fromWire(&w).(*coreError)
+	return nil
+}
+
+func toWire(err error, settings *encodeSettings) *wireError {
+	e, ok := err.(*coreError)
+	if !ok {
+		w := &wireError{Msg: err.Error()}
+		if cause := errors.Unwrap(err); cause != nil {
+			w.Cause = toWire(cause, settings)
+		}
+		return w
+	}
+	w := &wireError{
+		Type:  e.name,
+		Code:  e.Code(),
+		Msg:   e.message,
+		Attrs: e.attrs,
+		Ctx:   e.ctxAttrs,
+		Field: e.field,
+	}
+	if e.cause != nil {
+		w.Cause = toWire(e.cause, settings)
+	}
+	if settings.withStack {
+		for _, f := range e.StackFrames() {
+			w.Stack = append(w.Stack, wireFrame{Function: f.Name, File: f.File, Line: f.Line})
+		}
+	}
+	return w
+}
+
+func fromWire(w *wireError) error {
+	if w.Type == "" {
+		if w.Cause == nil {
+			return errors.New(w.Msg)
+		}
+		return &wrapError{msg: w.Msg, cause: fromWire(w.Cause)}
+	}
+	factory := lookup(w.Code)
+	if factory == nil {
+		// keep the remote identity while matching RemoteError
+		factory = &errorFactory{
+			name:   w.Type,
+			code:   w.Code,
+			parent: RemoteError.(*errorFactory),
+		}
+		factory.matcher = matcher{factory}
+	}
+	e := &coreError{
+		name:     factory.name,
+		factory:  factory,
+		message:  w.Msg,
+		attrs:    w.Attrs,
+		ctxAttrs: w.Ctx,
+		field:    w.Field,
+	}
+	if w.Cause != nil {
+		e.cause = fromWire(w.Cause)
+	}
+	if len(w.Stack) > 0 {
+		frames := make(stackFrames, len(w.Stack))
+		for i, f := range w.Stack {
+			frames[i] = Frame{
+				Name:     f.Function,
+				Function: shortFunctionName(f.Function),
+				File:     f.File,
+				Line:     f.Line,
+			}
+		}
+		e.stackFrames = symbolizedStack(frames)
+	}
+	return e
+}
+
+// wrapError is a decoded error which is not a core error but wraps one.
+type wrapError struct {
+	msg   string
+	cause error
+}
+
+func (e *wrapError) Error() string {
+	return e.msg
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.cause
+}
+
+// wireAttrs serializes attributes as a JSON object keeping their order.
+type wireAttrs []slog.Attr
+
+func (attrs wireAttrs) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, attr := range attrs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(attr.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		var value []byte
+		if v := attr.Value.Resolve(); v.Kind() == slog.KindGroup {
+			value, err = wireAttrs(v.Group()).MarshalJSON()
+		} else {
+			value, err = json.Marshal(v.Any())
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (attrs *wireAttrs) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return err
+	}
+	if value.Kind() != slog.KindGroup {
+		return fmt.Errorf("attrs: unexpected value %v", value)
+	}
+	*attrs = value.Group()
+	return nil
+}
+
+func decodeValue(decoder *json.Decoder) (slog.Value, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			var attrs []slog.Attr
+			for decoder.More() {
+				token, err := decoder.Token()
+				if err != nil {
+					return slog.Value{}, err
+				}
+				key, _ := token.(string)
+				value, err := decodeValue(decoder)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				attrs = append(attrs, slog.Attr{Key: key, Value: value})
+			}
+			_, err = decoder.Token()
+			return slog.GroupValue(attrs...), err
+		}
+		var values []any
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			values = append(values, value.Any())
+		}
+		_, err = decoder.Token()
+		return slog.AnyValue(values), err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return slog.Int64Value(i), nil
+		}
+		f, err := t.Float64()
+		return slog.Float64Value(f), err
+	case string:
+		return slog.StringValue(t), nil
+	case bool:
+		return slog.BoolValue(t), nil
+	}
+	return slog.AnyValue(nil), nil
+}
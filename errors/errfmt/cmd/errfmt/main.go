@@ -0,0 +1,14 @@
+// Command errfmt runs the errfmt analyzer as a go vet tool:
+//
+//	go vet -vettool=$(which errfmt) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/gotech-labs/core/errors/errfmt"
+)
+
+func main() {
+	unitchecker.Main(errfmt.Analyzer)
+}
@@ -0,0 +1,120 @@
+// Package errfmt defines an analyzer that checks the format strings and
+// attributes passed to the core error factories.
+package errfmt
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/gotech-labs/core/internal/fmtverb"
+)
+
+const errorsPath = "github.com/gotech-labs/core/errors"
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "errfmt",
+	Doc:      "check format verbs and key/value attributes passed to ErrorFactory methods",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// formatIndexes maps the ErrorFactory methods to the position of their
+// format parameter.
+var formatIndexes = map[string]int{
+	"New":         0,
+	"NewContext":  1,
+	"Wrap":        1,
+	"WrapContext": 2,
+	"Field":       1,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		index, ok := formatIndex(pass, call)
+		if !ok || len(call.Args) <= index || call.Ellipsis.IsValid() {
+			return
+		}
+		tv, ok := pass.TypesInfo.Types[call.Args[index]]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			return
+		}
+		format := constant.StringVal(tv.Value)
+		args := call.Args[index+1:]
+		count := fmtverb.Count(format)
+		if count > len(args) {
+			pass.Reportf(call.Lparen, "format %q reads %d args, but call has %d", format, count, len(args))
+			return
+		}
+		checkAttrs(pass, args[count:])
+	})
+	return nil, nil
+}
+
+func formatIndex(pass *analysis.Pass, call *ast.CallExpr) (int, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return 0, false
+	}
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != errorsPath {
+		return 0, false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return 0, false
+	}
+	recvType := recv.Type()
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		recvType = ptr.Elem()
+	}
+	named, ok := recvType.(*types.Named)
+	if !ok {
+		return 0, false
+	}
+	switch named.Obj().Name() {
+	case "ErrorFactory", "errorFactory":
+	default:
+		return 0, false
+	}
+	index, ok := formatIndexes[fn.Name()]
+	return index, ok
+}
+
+func checkAttrs(pass *analysis.Pass, args []ast.Expr) {
+	for i := 0; i < len(args); i++ {
+		typ := pass.TypesInfo.TypeOf(args[i])
+		switch {
+		case isString(typ):
+			if i+1 == len(args) {
+				pass.Reportf(args[i].Pos(), "missing value for attribute key %s", types.ExprString(args[i]))
+				return
+			}
+			i++
+		case isAttr(typ):
+		default:
+			pass.Reportf(args[i].Pos(), "unused format argument or attribute key %s of type %s; want string or slog.Attr",
+				types.ExprString(args[i]), typ)
+		}
+	}
+}
+
+func isString(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+func isAttr(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "log/slog" && obj.Name() == "Attr"
+}
@@ -0,0 +1,12 @@
+package errfmt_test
+
+import (
+	"testing"
+
+	"github.com/gotech-labs/core/errors/errfmt"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), errfmt.Analyzer, "a")
+}
@@ -0,0 +1,30 @@
+package a
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/gotech-labs/core/errors"
+)
+
+func calls(ctx context.Context, id int, name string, args []any) {
+	_ = errors.ValidationError.New("user name is a required field")
+	_ = errors.ValidationError.New("user %d not found", id)
+	_ = errors.ValidationError.New("user %d not found", id, "name", name)
+	_ = errors.ValidationError.New("user not found", "id", id, slog.String("name", name))
+	_ = errors.ValidationError.New("quota exceeded 100%%")
+	_ = errors.ValidationError.New("user %d not found", args...)
+	_ = errors.ValidationError.Wrap(io.EOF, "read %s", name)
+	_ = errors.ValidationError.Field("email", "must not be longer than %d", 255)
+	_ = errors.ValidationError.NewContext(ctx, "user %d not found", id, "name", name)
+	_ = errors.ValidationError.WrapContext(ctx, io.EOF, "read %s", name)
+
+	_ = errors.ValidationError.New("user %d (%s) not found", id)    // want `format "user %d \(%s\) not found" reads 2 args, but call has 1`
+	_ = errors.ValidationError.New("user not found", id)            // want `unused format argument or attribute key id of type int; want string or slog.Attr`
+	_ = errors.ValidationError.New("user not found", "id")          // want `missing value for attribute key "id"`
+	_ = errors.ValidationError.Wrap(io.EOF, "read %s")              // want `format "read %s" reads 1 args, but call has 0`
+	_ = errors.ValidationError.Field("email", "must be %s")         // want `format "must be %s" reads 1 args, but call has 0`
+	_ = errors.ValidationError.NewContext(ctx, "user %d not found") // want `format "user %d not found" reads 1 args, but call has 0`
+	_ = errors.ValidationError.WrapContext(ctx, io.EOF, "read", id) // want `unused format argument or attribute key id of type int; want string or slog.Attr`
+}
@@ -0,0 +1,17 @@
+package errors
+
+import "context"
+
+type Error interface {
+	error
+}
+
+type ErrorFactory interface {
+	New(format string, args ...any) Error
+	NewContext(ctx context.Context, format string, args ...any) Error
+	Wrap(cause error, format string, args ...any) Error
+	WrapContext(ctx context.Context, cause error, format string, args ...any) Error
+	Field(name string, format string, args ...any) Error
+}
+
+var ValidationError ErrorFactory
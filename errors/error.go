@@ -1,44 +1,92 @@
 package errors
 
 import (
-	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gotech-labs/core/internal/fmtverb"
 )
 
 type Error interface {
 	error
 	Type() Type
+	Code() Code
+	Message() string
+	Attrs() []slog.Attr
 	Unwrap() error
 	StackTrace() StackTrace
+	StackFrames() []Frame
+	Fingerprint() string
 	buildStackTrace(traceLines ...string) []string
 }
 
 type StackTrace []string
 
+const stackTraceKey = "stack_trace"
+
 type coreError struct {
-	name        Type
-	cause       error
-	message     string
-	attrs       []any
-	stackFrames stackFrames
+	name     Type
+	factory  *errorFactory
+	cause    error
+	template string
+	message  string
+	attrs    []slog.Attr
+	ctxAttrs []slog.Attr
+	// field is the name of the field of the errors created by Field.
+	field       string
+	stackFrames *stack
 }
 
 func (e *coreError) Type() Type {
 	return e.name
 }
 
-func (e *coreError) Unwrap() error {
-	next := AsError(e.cause)
-	if next != nil {
-		return next.Unwrap()
+func (e *coreError) Code() Code {
+	if e.factory == nil {
+		return ""
+	}
+	return e.factory.code
+}
+
+func (e *coreError) Message() string {
+	return e.message
+}
+
+func (e *coreError) Attrs() []slog.Attr {
+	attrs := mergeAttrs(e.ctxAttrs, e.attrs)
+	if next := AsError(e.cause); next != nil {
+		return mergeAttrs(next.Attrs(), attrs)
 	}
+	return attrs
+}
+
+func (e *coreError) Unwrap() error {
 	return e.cause
 }
 
+func (e *coreError) Is(target error) bool {
+	factory, ok := target.(*errorFactory)
+	return ok && e.factory != nil && e.factory.isA(factory)
+}
+
+func (e *coreError) As(target any) bool {
+	if factory, ok := target.(*ErrorFactory); ok && e.factory != nil {
+		*factory = e.factory
+		return true
+	}
+	return false
+}
+
 func (e *coreError) StackTrace() StackTrace {
 	return StackTrace(e.buildStackTrace())
 }
 
+// StackFrames returns the frames captured where the error was created.
+func (e *coreError) StackFrames() []Frame {
+	return e.stackFrames.StackFrames()
+}
+
 func (e *coreError) buildStackTrace(traceLines ...string) []string {
 	if e.cause != nil {
 		if next := AsError(e.cause); next != nil {
@@ -49,7 +97,7 @@ func (e *coreError) buildStackTrace(traceLines ...string) []string {
 		}
 	}
 	traceLines = append(traceLines, fmt.Sprintf("%s: %s", e.name, e.message))
-	traceLines = append(traceLines, e.stackFrames.traceLines()...)
+	traceLines = append(traceLines, e.stackFrames.StackFrames().traceLines()...)
 	return traceLines
 }
 
@@ -61,19 +109,38 @@ func (e *coreError) Error() string {
 	return value
 }
 
-func (e *coreError) MarshalJSON() ([]byte, error) {
-	v := struct {
-		Msg        string     `json:"msg"`
-		Cause      string     `json:"cause,omitempty"`
-		StackTrace StackTrace `json:"stack_trace,omitempty"`
-	}{
-		Msg:        e.message,
-		StackTrace: e.StackTrace(),
+func (e *coreError) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 6)
+	attrs = append(attrs,
+		slog.String("type", string(e.name)),
+		slog.String("code", string(e.Code())),
+		slog.String("msg", e.message),
+	)
+	if values := e.Attrs(); len(values) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "attrs", Value: slog.GroupValue(values...)})
 	}
 	if e.cause != nil {
-		v.Cause = e.cause.Error()
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: causeValue(e.cause)})
 	}
-	return json.Marshal(&v)
+	attrs = append(attrs, slog.Any(stackTraceKey, e.StackTrace()))
+	return slog.GroupValue(attrs...)
+}
+
+func causeValue(cause error) slog.Value {
+	e, ok := AsError(cause).(*coreError)
+	if !ok {
+		return slog.StringValue(cause.Error())
+	}
+	attrs := make([]slog.Attr, 0, 4)
+	attrs = append(attrs,
+		slog.String("type", string(e.name)),
+		slog.String("code", string(e.Code())),
+		slog.String("msg", e.message),
+	)
+	if e.cause != nil {
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: causeValue(e.cause)})
+	}
+	return slog.GroupValue(attrs...)
 }
 
 func (e *coreError) Format(s fmt.State, verb rune) {
@@ -84,7 +151,10 @@ func (e *coreError) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			fmt.Fprintf(s, "%+v", *e)
+			var b strings.Builder
+			printer := &prettyPrinter{w: &b, settings: currentStackSettings.Load()}
+			printer.print(e)
+			fmt.Fprint(s, strings.TrimSuffix(b.String(), "\n"))
 			return
 		}
 		fallthrough
@@ -95,12 +165,30 @@ func (e *coreError) Format(s fmt.State, verb rune) {
 	}
 }
 
-func newError(name Type, cause error, message string, args ...any) *coreError {
+func newError(factory *errorFactory, cause error, format string, args ...any) *coreError {
+	message, attrs := formatMessage(format, args)
 	return &coreError{
-		name:        name,
+		name:        factory.name,
+		factory:     factory,
 		cause:       cause,
+		template:    format,
 		message:     message,
-		attrs:       args,
-		stackFrames: callers(4).StackFrames(),
+		attrs:       argsToAttrs(attrs),
+		stackFrames: callers(4, factory.stackMode),
+	}
+}
+
+// formatMessage interpolates the operands of the format verbs and returns
+// the remaining args, which are treated as key/value attributes.
+// A message whose verbs cannot be satisfied, or whose interpolation would
+// split the key/value pairs, is treated as a literal (e.g. "50% off").
+func formatMessage(format string, args []any) (string, []any) {
+	if !strings.Contains(format, "%") {
+		return format, args
+	}
+	n := fmtverb.Count(format)
+	if n > len(args) || (!isAttrArgs(args[n:]) && isAttrArgs(args)) {
+		return format, args
 	}
+	return fmt.Sprintf(format, args[:n]...), args[n:]
 }
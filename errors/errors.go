@@ -2,28 +2,77 @@ package errors
 
 import (
 	"errors"
+	"net/http"
 )
 
 var (
-	ValidationError             = Define("invalid parameter")
-	IllegalArgumentError        = Define("illegal argument")
-	InitializationError         = Define("initialization error")
-	AuthenticationRequiredError = Define("authentication required")
-	UnexpectedError             = Define("unexpected error")
+	ValidationError = coreNamespace.Define("invalid parameter",
+		WithHTTPStatus(http.StatusBadRequest),
+		WithGRPCCode(InvalidArgument))
+	IllegalArgumentError = coreNamespace.Define("illegal argument",
+		WithHTTPStatus(http.StatusBadRequest),
+		WithGRPCCode(InvalidArgument))
+	InitializationError = coreNamespace.Define("initialization error",
+		WithHTTPStatus(http.StatusInternalServerError),
+		WithGRPCCode(Internal))
+	AuthenticationRequiredError = coreNamespace.Define("authentication required",
+		WithHTTPStatus(http.StatusUnauthorized),
+		WithGRPCCode(Unauthenticated))
+	UnexpectedError = coreNamespace.Define("unexpected error",
+		WithHTTPStatus(http.StatusInternalServerError),
+		WithGRPCCode(Internal))
 )
 
-func Define(name string) ErrorFactory {
-	return &errorFactory{
-		name: Type(name),
+// Define defines a new error type. It panics if the code of the type is
+// already used by a type defined elsewhere.
+func Define(name string, options ...option) ErrorFactory {
+	return define("", name, options)
+}
+
+func define(ns Namespace, name string, options []option) ErrorFactory {
+	factory := &errorFactory{
+		name:   Type(name),
+		origin: callerOrigin(3),
+	}
+	factory.matcher = matcher{factory}
+	for _, option := range options {
+		option(factory)
 	}
+	if factory.code == "" {
+		factory.code = defaultCode(name)
+	}
+	factory.code = ns.code(factory.code)
+	if factory.messageKey == "" {
+		factory.messageKey = string(factory.code)
+	}
+	register(factory)
+	return factory
 }
 
+// Unwrap returns the immediate cause of err.
 func Unwrap(err error) error {
-	target := AsError(err)
-	if target == nil {
-		return errors.Unwrap(err)
+	return errors.Unwrap(err)
+}
+
+// Chain returns err followed by its causes, from the outermost to the root.
+func Chain(err error) []error {
+	var chain []error
+	for ; err != nil; err = errors.Unwrap(err) {
+		chain = append(chain, err)
 	}
-	return target.Unwrap()
+	return chain
+}
+
+// Root returns the innermost cause of err.
+func Root(err error) error {
+	for err != nil {
+		cause := errors.Unwrap(err)
+		if cause == nil {
+			return err
+		}
+		err = cause
+	}
+	return nil
 }
 
 func AsError(err error) Error {
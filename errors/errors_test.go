@@ -1,14 +1,24 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	//. "github.com/gotech-labs/core/errors"
+	"github.com/gotech-labs/core/internal/ctxattr"
+	"github.com/gotech-labs/core/log"
+	"github.com/gotech-labs/core/system"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -21,6 +31,10 @@ func TestAsError(t *testing.T) {
 }
 
 func TestUnwrap(t *testing.T) {
+	var (
+		cause   = errors.New("mysql open error")
+		wrapErr = InitializationError.Wrap(cause, "database access error")
+	)
 	for _, in := range []struct {
 		name     string
 		err      error
@@ -43,26 +57,438 @@ func TestUnwrap(t *testing.T) {
 		},
 		{
 			name:     "test - wrap error",
-			err:      InitializationError.Wrap(errors.New("mysql open error"), "database access error"),
-			expected: errors.New("mysql open error"),
+			err:      wrapErr,
+			expected: cause,
+		},
+		{
+			name:     "test - nested error",
+			err:      UnexpectedError.Wrap(wrapErr, "nested error message"),
+			expected: wrapErr,
+		},
+	} {
+		assert.Equal(t, in.expected, Unwrap(in.err), in.name)
+		assert.Equal(t, in.expected, errors.Unwrap(in.err), in.name)
+	}
+}
+
+func TestChain(t *testing.T) {
+	var (
+		cause   = io.EOF
+		wrapErr = InitializationError.Wrap(fmt.Errorf("read config: %w", cause), "database access error")
+		nestErr = UnexpectedError.Wrap(wrapErr, "nested error message")
+	)
+	assert.Nil(t, Chain(nil))
+	assert.Nil(t, Root(nil))
+	assert.Equal(t, []error{cause}, Chain(cause))
+	assert.Equal(t, cause, Root(cause))
+	chain := Chain(nestErr)
+	require.Len(t, chain, 4)
+	assert.Equal(t, nestErr, chain[0])
+	assert.Equal(t, wrapErr, chain[1])
+	assert.Equal(t, "read config: EOF", chain[2].Error())
+	assert.Equal(t, cause, chain[3])
+	assert.Equal(t, cause, Root(nestErr))
+
+	var e Error
+	require.True(t, errors.As(errors.Unwrap(nestErr), &e))
+	assert.Equal(t, Type("initialization error"), e.Type())
+	assert.True(t, InitializationError.Is(nestErr))
+}
+
+func TestErrorAttrs(t *testing.T) {
+	for _, in := range []struct {
+		name     string
+		err      Error
+		expected []slog.Attr
+	}{
+		{
+			name:     "test - no attrs",
+			err:      ValidationError.New("user name is a required field"),
+			expected: nil,
+		},
+		{
+			name: "test - key values",
+			err:  ValidationError.New("user name is a required field", "userID", 123, slog.String("field", "name")),
+			expected: []slog.Attr{
+				slog.Int("userID", 123),
+				slog.String("field", "name"),
+			},
+		},
+		{
+			name: "test - bad key",
+			err:  ValidationError.New("user name is a required field", 123, "dangling"),
+			expected: []slog.Attr{
+				slog.Int("!BADKEY", 123),
+				slog.String("!BADKEY", "dangling"),
+			},
 		},
 		{
 			name: "test - nested error",
 			err: UnexpectedError.Wrap(
-				InitializationError.Wrap(errors.New("mysql open error"), "database access error"), "nested error message"),
-			expected: errors.New("mysql open error"),
+				InitializationError.Wrap(errors.New("mysql open error"), "database access error", "host", "db1", "retry", 1),
+				"nested error message", "retry", 3, "userID", 123),
+			expected: []slog.Attr{
+				slog.String("host", "db1"),
+				slog.Int("retry", 3),
+				slog.Int("userID", 123),
+			},
 		},
 	} {
-		assert.Equal(t, in.expected, Unwrap(in.err))
+		actual := in.err.Attrs()
+		require.Len(t, actual, len(in.expected), in.name)
+		for i, attr := range in.expected {
+			assert.True(t, attr.Equal(actual[i]), in.name)
+		}
 	}
 }
 
+func TestErrorMessage(t *testing.T) {
+	for _, in := range []struct {
+		name          string
+		err           Error
+		expectedMsg   string
+		expectedAttrs []slog.Attr
+	}{
+		{
+			name:        "test - plain message",
+			err:         ValidationError.New("user name is a required field"),
+			expectedMsg: "user name is a required field",
+		},
+		{
+			name:        "test - format verbs",
+			err:         ValidationError.New("user %d not found (%s)", 123, "taro"),
+			expectedMsg: "user 123 not found (taro)",
+		},
+		{
+			name:          "test - format verbs and attrs",
+			err:           ValidationError.New("user %d not found", 123, "tenant", "acme"),
+			expectedMsg:   "user 123 not found",
+			expectedAttrs: []slog.Attr{slog.String("tenant", "acme")},
+		},
+		{
+			name:          "test - escaped percent",
+			err:           ValidationError.New("quota exceeded 100%%", "userID", 123),
+			expectedMsg:   "quota exceeded 100%",
+			expectedAttrs: []slog.Attr{slog.Int("userID", 123)},
+		},
+		{
+			name:        "test - missing operand",
+			err:         ValidationError.New("user %d not found"),
+			expectedMsg: "user %d not found",
+		},
+		{
+			name:        "test - literal percent",
+			err:         ValidationError.New("50% off"),
+			expectedMsg: "50% off",
+		},
+		{
+			name:          "test - literal percent and attrs",
+			err:           ValidationError.New("100% sure", "k", 1),
+			expectedMsg:   "100% sure",
+			expectedAttrs: []slog.Attr{slog.Int("k", 1)},
+		},
+		{
+			name:          "test - wrap error",
+			err:           InitializationError.Wrap(errors.New("mysql open error"), "open %s", "db1", "retry", 3),
+			expectedMsg:   "open db1",
+			expectedAttrs: []slog.Attr{slog.Int("retry", 3)},
+		},
+	} {
+		assert.Equal(t, in.expectedMsg, fmt.Sprint(in.err), in.name)
+		actual := in.err.Attrs()
+		require.Len(t, actual, len(in.expectedAttrs), in.name)
+		for i, attr := range in.expectedAttrs {
+			assert.True(t, attr.Equal(actual[i]), in.name)
+		}
+	}
+}
+
+func TestErrorLogValue(t *testing.T) {
+	err := UnexpectedError.Wrap(
+		InitializationError.Wrap(errors.New("mysql open error"), "database access error", "host", "db1"),
+		"nested error message", "userID", 123)
+	value := err.(slog.LogValuer).LogValue()
+	require.Equal(t, slog.KindGroup, value.Kind())
+	attrs := value.Group()
+	require.Len(t, attrs, 6)
+	assert.Equal(t, "type=unexpected error", attrs[0].String())
+	assert.Equal(t, "code=core.unexpected_error", attrs[1].String())
+	assert.Equal(t, "msg=nested error message", attrs[2].String())
+	assert.Equal(t, "attrs=[host=db1 userID=123]", attrs[3].String())
+	assert.Equal(t, "cause=[type=initialization error code=core.initialization_error msg=database access error cause=mysql open error]", attrs[4].String())
+	assert.Equal(t, stackTraceKey, attrs[5].Key)
+	assert.Equal(t, err.StackTrace(), attrs[5].Value.Any())
+}
+
 func TestErrorType(t *testing.T) {
 	assert.False(t, ValidationError.Is(nil))
 	assert.False(t, ValidationError.Is(errors.New("mysql open error")))
 	assert.True(t, ValidationError.Is(ValidationError.New("user name is a required field")))
 }
 
+func TestStdlibIs(t *testing.T) {
+	NotFoundError := Define("resource not found", WithParent(ValidationError))
+	UserNotFoundError := Define("user not found", WithParent(NotFoundError))
+	for _, in := range []struct {
+		name     string
+		err      error
+		target   error
+		expected bool
+	}{
+		{
+			name:     "test - nil",
+			err:      nil,
+			target:   ValidationError,
+			expected: false,
+		},
+		{
+			name:     "test - golang error",
+			err:      errors.New("mysql open error"),
+			target:   ValidationError,
+			expected: false,
+		},
+		{
+			name:     "test - same type",
+			err:      ValidationError.New("user name is a required field"),
+			target:   ValidationError,
+			expected: true,
+		},
+		{
+			name:     "test - other type",
+			err:      ValidationError.New("user name is a required field"),
+			target:   UnexpectedError,
+			expected: false,
+		},
+		{
+			name:     "test - wrapped by fmt.Errorf",
+			err:      fmt.Errorf("handler: %w", ValidationError.New("user name is a required field")),
+			target:   ValidationError,
+			expected: true,
+		},
+		{
+			name: "test - joined errors",
+			err: errors.Join(
+				errors.New("mysql open error"),
+				fmt.Errorf("handler: %w", AuthenticationRequiredError.New("token expired"))),
+			target:   AuthenticationRequiredError,
+			expected: true,
+		},
+		{
+			name:     "test - child type",
+			err:      NotFoundError.New("user 123"),
+			target:   ValidationError,
+			expected: true,
+		},
+		{
+			name:     "test - grandchild type",
+			err:      UserNotFoundError.New("user 123"),
+			target:   ValidationError,
+			expected: true,
+		},
+		{
+			name:     "test - parent type",
+			err:      ValidationError.New("user 123"),
+			target:   NotFoundError,
+			expected: false,
+		},
+		{
+			name:     "test - cause",
+			err:      InitializationError.Wrap(io.EOF, "failed to read config"),
+			target:   io.EOF,
+			expected: true,
+		},
+		{
+			name:     "test - other factory",
+			err:      ValidationError,
+			target:   IllegalArgumentError,
+			expected: false,
+		},
+		{
+			name:     "test - wrapped factory",
+			err:      fmt.Errorf("handler: %w", ValidationError),
+			target:   ValidationError,
+			expected: true,
+		},
+		{
+			name:     "test - wrapped factory and error target",
+			err:      fmt.Errorf("handler: %w", ValidationError),
+			target:   ValidationError.New("user 123"),
+			expected: false,
+		},
+	} {
+		assert.Equal(t, in.expected, errors.Is(in.err, in.target), in.name)
+		if factory, ok := in.target.(ErrorFactory); ok {
+			assert.Equal(t, in.expected, factory.Is(in.err), in.name)
+		}
+	}
+}
+
+func TestStdlibAs(t *testing.T) {
+	err := fmt.Errorf("handler: %w", ValidationError.New("user name is a required field"))
+	var factory ErrorFactory
+	require.True(t, errors.As(err, &factory))
+	assert.Equal(t, ValidationError, factory)
+	var e Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, Type("invalid parameter"), e.Type())
+	assert.False(t, errors.As(io.EOF, &factory))
+}
+
+func TestMulti(t *testing.T) {
+	assert.Nil(t, Join())
+	assert.Nil(t, Join(nil, nil))
+	assert.Nil(t, Append(nil))
+	assert.Nil(t, Append((*Multi)(nil), (*Multi)(nil)))
+	assert.Equal(t, []error{io.EOF}, Append((*Multi)(nil), io.EOF).(*Multi).Errors())
+
+	var (
+		cause    = errors.New("mysql open error")
+		fieldErr = ValidationError.Field("email", "must not be empty")
+	)
+	err := Append(nil, fieldErr, nil)
+	err = Append(err, ValidationError.Field("name", "must not be longer than %d characters", 32))
+	err = Append(err, Join(ValidationError.Field("email", "must be an email address"), cause))
+
+	var multi *Multi
+	require.True(t, errors.As(err, &multi))
+	require.Len(t, multi.Errors(), 4)
+	assert.Equal(t, "4 errors occurred: invalid parameter: must not be empty; "+
+		"invalid parameter: must not be longer than 32 characters; "+
+		"invalid parameter: must be an email address; mysql open error", err.Error())
+	assert.Equal(t, fieldErr.Error(), Join(fieldErr).Error())
+	assert.True(t, errors.Is(err, cause))
+	assert.True(t, ValidationError.Is(err))
+	assert.False(t, UnexpectedError.Is(err))
+	assert.Equal(t, map[string][]string{
+		"email": {"must not be empty", "must be an email address"},
+		"name":  {"must not be longer than 32 characters"},
+	}, multi.Fields())
+	assert.Equal(t, map[string][]string{"email": {"must not be empty"}}, Fields(fieldErr))
+	assert.Nil(t, Fields(cause))
+	assert.Nil(t, Fields(ValidationError.New("bad input", "field", "email")))
+
+	stackTrace := multi.StackTrace()
+	require.Len(t, stackTrace, 7)
+	assert.Equal(t, "invalid parameter: must not be empty", stackTrace[0])
+	assert.Equal(t, "invalid parameter: must not be longer than 32 characters", stackTrace[2])
+	assert.Equal(t, "    at "+currentDir+"/errors_test.go:xxx (TestMulti)", cutLineNumber(stackTrace[3]))
+	assert.Equal(t, "Caused by: mysql open error", stackTrace[6])
+
+	data, e := json.Marshal(Join(ValidationError.Field("email", "must not be empty"), cause))
+	require.NoError(t, e)
+	var values []json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &values))
+	require.Len(t, values, 2)
+	expected := strings.ReplaceAll(`{
+		"type": "invalid parameter",
+		"code": "core.invalid_parameter",
+		"msg": "must not be empty",
+		"attrs": {"field": "email"},
+		"field": "email",
+		"stack": [
+			{"function": "github.com/gotech-labs/core/errors.TestMulti", "file": "${CURRENT_DIR}/errors_test.go", "line": 0}
+		]
+	}`, "${CURRENT_DIR}", currentDir)
+	assert.JSONEq(t, expected, cutStackLines(t, values[0]))
+	assert.JSONEq(t, `{"msg": "mysql open error"}`, string(values[1]))
+
+	assert.Equal(t, http.StatusBadRequest, StatusOf(Join(fieldErr)).HTTPStatus)
+	assert.Equal(t, http.StatusInternalServerError, StatusOf(err).HTTPStatus)
+}
+
+func TestStatusOf(t *testing.T) {
+	NotFoundError := Define("not found",
+		WithHTTPStatus(http.StatusNotFound),
+		WithGRPCCode(NotFound),
+		WithPublicMessage("resource not found"))
+	TemporaryError := Define("temporary error", WithRetryable(true))
+	FileAccessError := Define("file i/o error")
+	ConflictError := Define("conflict", WithParent(ValidationError), WithRetryable(true),
+		WithPublicMessage("the resource was modified concurrently"))
+	for _, in := range []struct {
+		name     string
+		err      error
+		expected Status
+	}{
+		{
+			name: "test - inherited status",
+			err:  ConflictError.New("version 3 != 4"),
+			expected: Status{
+				HTTPStatus:    http.StatusBadRequest,
+				GRPCCode:      InvalidArgument,
+				Retryable:     true,
+				PublicMessage: "the resource was modified concurrently",
+			},
+		},
+		{
+			name:     "test - nil",
+			err:      nil,
+			expected: Status{HTTPStatus: http.StatusOK, GRPCCode: OK},
+		},
+		{
+			name:     "test - golang error",
+			err:      errors.New("mysql open error"),
+			expected: Status{HTTPStatus: http.StatusInternalServerError, GRPCCode: Unknown},
+		},
+		{
+			name:     "test - predefined error",
+			err:      ValidationError.New("user name is a required field"),
+			expected: Status{HTTPStatus: http.StatusBadRequest, GRPCCode: InvalidArgument},
+		},
+		{
+			name:     "test - authentication error",
+			err:      AuthenticationRequiredError.New("token expired"),
+			expected: Status{HTTPStatus: http.StatusUnauthorized, GRPCCode: Unauthenticated},
+		},
+		{
+			name: "test - custom error",
+			err:  NotFoundError.New("user not found"),
+			expected: Status{
+				HTTPStatus:    http.StatusNotFound,
+				GRPCCode:      NotFound,
+				PublicMessage: "resource not found",
+			},
+		},
+		{
+			name:     "test - retryable error",
+			err:      TemporaryError.New("connection reset"),
+			expected: Status{HTTPStatus: http.StatusInternalServerError, GRPCCode: Unknown, Retryable: true},
+		},
+		{
+			name:     "test - wrapped by golang error",
+			err:      fmt.Errorf("handler: %w", AuthenticationRequiredError.New("token expired")),
+			expected: Status{HTTPStatus: http.StatusUnauthorized, GRPCCode: Unauthenticated},
+		},
+		{
+			name:     "test - wrapped by error without status",
+			err:      FileAccessError.Wrap(ValidationError.New("illegal path"), "failed to read directory"),
+			expected: Status{HTTPStatus: http.StatusBadRequest, GRPCCode: InvalidArgument},
+		},
+		{
+			name:     "test - wrapped by error with status",
+			err:      UnexpectedError.Wrap(ValidationError.New("illegal path"), "failed to read directory"),
+			expected: Status{HTTPStatus: http.StatusInternalServerError, GRPCCode: Internal},
+		},
+		{
+			name:     "test - inherited from parent type",
+			err:      Define("account not found", WithParent(NotFoundError)).New("user 123"),
+			expected: Status{HTTPStatus: http.StatusNotFound, GRPCCode: NotFound, PublicMessage: "resource not found"},
+		},
+		{
+			name:     "test - context canceled",
+			err:      FileAccessError.Wrap(fmt.Errorf("query: %w", context.Canceled), "failed to read table"),
+			expected: Status{HTTPStatus: 499, GRPCCode: Canceled},
+		},
+		{
+			name:     "test - context deadline exceeded",
+			err:      context.DeadlineExceeded,
+			expected: Status{HTTPStatus: http.StatusGatewayTimeout, GRPCCode: DeadlineExceeded},
+		},
+	} {
+		assert.Equal(t, in.expected, StatusOf(in.err), in.name)
+	}
+}
+
 /*
 func TestDefinedErrorType(t *testing.T) {
 	testData := testData()
@@ -101,7 +527,55 @@ func TestErrorFormat(t *testing.T) {
 	assert.Equal(t, "user name is a required field", fmt.Sprint(err))
 	assert.Equal(t, "user name is a required field", fmt.Sprintf("%+s", err))
 	assert.Equal(t, "\"user name is a required field\"", fmt.Sprintf("%+q", err))
-	assert.Equal(t, "{name:validation error cause:<nil> message:user name is a required field attrs:[] stackFrames:[]}", fmt.Sprintf("%+v", err))
+	assert.Equal(t, "validation error: user name is a required field", fmt.Sprintf("%+v", err))
+}
+
+func TestErrorPrettyFormat(t *testing.T) {
+	defer currentStackSettings.Store(currentStackSettings.Load())
+
+	err := UnexpectedError.Wrap(
+		InitializationError.Wrap(errors.New("mysql open error"), "database access error"), "nested error message")
+	assert.Equal(t, strings.Join([]string{
+		"unexpected error: nested error message",
+		"    at ${CURRENT_DIR}/errors_test.go:xxx (TestErrorPrettyFormat)",
+		"Caused by: initialization error: database access error",
+		"    at ${CURRENT_DIR}/errors_test.go:xxx (TestErrorPrettyFormat)",
+		"Caused by: mysql open error",
+	}, "\n"), strings.ReplaceAll(cutLineNumber(fmt.Sprintf("%+v", err)), currentDir, "${CURRENT_DIR}"))
+
+	// runs of standard library frames are elided
+	remote := &coreError{
+		name:    Type("remote error"),
+		message: "connection reset",
+		stackFrames: symbolizedStack(stackFrames{
+			{Name: "github.com/xxx/client.Get", Function: "Get", File: "client.go", Line: 10},
+			{Name: "net/http.HandlerFunc.ServeHTTP", Function: "HandlerFunc.ServeHTTP", File: "server.go", Line: 2171},
+			{Name: "net/http.(*ServeMux).ServeHTTP", Function: "(*ServeMux).ServeHTTP", File: "server.go", Line: 2688},
+			{Name: "net/http.serverHandler.ServeHTTP", Function: "serverHandler.ServeHTTP", File: "server.go", Line: 3142},
+			{Name: "main.main", Function: "main", File: "main.go", Line: 5},
+			{Name: "fmt.Sprintf", Function: "Sprintf", File: "print.go", Line: 239},
+		}),
+	}
+	assert.Equal(t, strings.Join([]string{
+		"remote error: connection reset",
+		"    at client.go:10 (Get)",
+		"    ... 3 standard library frames",
+		"    at main.go:5 (main)",
+		"    at print.go:239 (Sprintf)",
+	}, "\n"), fmt.Sprintf("%+v", remote))
+
+	SetStackOptions(WithColor(true))
+	remote.stackFrames = symbolizedStack(stackFrames{{Function: "Get", File: "client.go", Line: 10}})
+	assert.Equal(t, "\x1b[1m\x1b[31mremote error\x1b[0m: connection reset\n"+
+		"    at \x1b[2mclient.go:10\x1b[0m (\x1b[36mGet\x1b[0m)", fmt.Sprintf("%+v", remote))
+
+	SetStackOptions(WithColor(false), WithSourceLines(1))
+	err2 := ValidationError.New("user name is a required field")
+	lines := strings.Split(fmt.Sprintf("%+v", err2), "\n")
+	require.GreaterOrEqual(t, len(lines), 5)
+	assert.Regexp(t, `^        \d+ \|     SetStackOptions\(WithColor\(false\), WithSourceLines\(1\)\)$`, lines[2])
+	assert.Regexp(t, `^      > \d+ \|     err2 := ValidationError.New\("user name is a required field"\)$`, lines[3])
+	assert.Regexp(t, `^        \d+ \|     lines := `, lines[4])
 }
 
 func TestErrorTrace(t *testing.T) {
@@ -160,10 +634,11 @@ func TestErrorJSON(t *testing.T) {
 			name: "test - new error json",
 			err:  ValidationError.New("user name is a required field"),
 			expected: `{
+				"type": "invalid parameter",
+				"code": "core.invalid_parameter",
 				"msg": "user name is a required field",
-				"stack_trace": [
-					"invalid parameter: user name is a required field",
-					"    at ${CURRENT_DIR}/errors_test.go:xxx (TestErrorJSON)"
+				"stack": [
+					{"function": "github.com/gotech-labs/core/errors.TestErrorJSON", "file": "${CURRENT_DIR}/errors_test.go", "line": 0}
 				]
 			}`,
 		},
@@ -171,37 +646,318 @@ func TestErrorJSON(t *testing.T) {
 			name: "test - wrap error json",
 			err:  InitializationError.Wrap(errors.New("mysql open error"), "database access error"),
 			expected: `{
-				"cause": "mysql open error",
+				"type": "initialization error",
+				"code": "core.initialization_error",
 				"msg": "database access error",
-				"stack_trace": [
-					"Caused by: mysql open error",
-					"initialization error: database access error",
-					"    at ${CURRENT_DIR}/errors_test.go:xxx (TestErrorJSON)"
+				"cause": {"msg": "mysql open error"},
+				"stack": [
+					{"function": "github.com/gotech-labs/core/errors.TestErrorJSON", "file": "${CURRENT_DIR}/errors_test.go", "line": 0}
+				]
+			}`,
+		},
+		{
+			name: "test - attrs error json",
+			err:  ValidationError.New("user name is a required field", "userID", 123, slog.Group("request", "id", "abc")),
+			expected: `{
+				"type": "invalid parameter",
+				"code": "core.invalid_parameter",
+				"msg": "user name is a required field",
+				"attrs": {
+					"userID": 123,
+					"request": {"id": "abc"}
+				},
+				"stack": [
+					{"function": "github.com/gotech-labs/core/errors.TestErrorJSON", "file": "${CURRENT_DIR}/errors_test.go", "line": 0}
 				]
 			}`,
 		},
 		{
 			name: "test - nested error json",
 			err: UnexpectedError.Wrap(
-				InitializationError.Wrap(errors.New("mysql open error"), "database access error"), "nested error message"),
+				InitializationError.Wrap(errors.New("mysql open error"), "database access error", "host", "db1"),
+				"nested error message"),
 			expected: `{
-				"cause": "initialization error: database access error (mysql open error)",
+				"type": "unexpected error",
+				"code": "core.unexpected_error",
 				"msg": "nested error message",
-				"stack_trace": [
-					"Caused by: mysql open error",
-					"initialization error: database access error",
-					"    at ${CURRENT_DIR}/errors_test.go:xxx (TestErrorJSON)",
-					"unexpected error: nested error message",
-					"    at ${CURRENT_DIR}/errors_test.go:xxx (TestErrorJSON)"
+				"cause": {
+					"type": "initialization error",
+					"code": "core.initialization_error",
+					"msg": "database access error",
+					"attrs": {"host": "db1"},
+					"cause": {"msg": "mysql open error"},
+					"stack": [
+						{"function": "github.com/gotech-labs/core/errors.TestErrorJSON", "file": "${CURRENT_DIR}/errors_test.go", "line": 0}
+					]
+				},
+				"stack": [
+					{"function": "github.com/gotech-labs/core/errors.TestErrorJSON", "file": "${CURRENT_DIR}/errors_test.go", "line": 0}
 				]
 			}`,
 		},
 	} {
 		data, e := json.MarshalIndent(in.err, "", "  ")
 		require.NoError(t, e)
-		actual := cutLineNumber(string(data))
 		expected := strings.ReplaceAll(in.expected, "${CURRENT_DIR}", currentDir)
-		assert.JSONEq(t, expected, actual, in.name)
+		assert.JSONEq(t, expected, cutStackLines(t, data), in.name)
+	}
+	data, e := Marshal(ValidationError.New("user name is a required field"), WithStack(false))
+	require.NoError(t, e)
+	assert.JSONEq(t, `{"type": "invalid parameter", "code": "core.invalid_parameter", "msg": "user name is a required field"}`, string(data))
+	data, e = Marshal(errors.New("mysql open error"))
+	require.NoError(t, e)
+	assert.JSONEq(t, `{"msg": "mysql open error"}`, string(data))
+}
+
+func TestTypes(t *testing.T) {
+	ParentError := Define("registry parent error", WithHTTPStatus(http.StatusConflict))
+	for i := 0; i < 2; i++ {
+		Define("registry child error", WithParent(ParentError))
+	}
+	definitions := map[Type]Definition{}
+	for i, definition := range Types() {
+		definitions[definition.Name] = definition
+		if i > 0 {
+			assert.Less(t, Types()[i-1].Code, definition.Code)
+		}
+	}
+	assert.Equal(t, Definition{
+		Name:       "invalid parameter",
+		Code:       "core.invalid_parameter",
+		Package:    "github.com/gotech-labs/core/errors",
+		Status:     Status{HTTPStatus: http.StatusBadRequest, GRPCCode: InvalidArgument},
+		MessageKey: "core.invalid_parameter",
+	}, definitions["invalid parameter"])
+	assert.Equal(t, Definition{
+		Name:       "registry child error",
+		Code:       "registry_child_error",
+		Package:    "github.com/gotech-labs/core/errors",
+		Parent:     "registry parent error",
+		MessageKey: "registry_child_error",
+	}, definitions["registry child error"])
+
+	recovered := func() (recovered any) {
+		defer func() {
+			recovered = recover()
+		}()
+		Define("registry parent error")
+		return nil
+	}()
+	assert.Equal(t, `errors: code "registry_parent_error" of type "registry parent error" is already defined in `+
+		`github.com/gotech-labs/core/errors (`+currentDir+`/errors_test.go:xxx)`,
+		regexp.MustCompile(`:\d+\)$`).ReplaceAllString(fmt.Sprint(recovered), ":xxx)"))
+	definition, ok := Lookup("registry_child_error")
+	require.True(t, ok)
+	assert.Equal(t, definitions["registry child error"], definition)
+	_, ok = Lookup("registry_unknown_error")
+	assert.False(t, ok)
+
+	var buf strings.Builder
+	log.SetGlobalLogger(&buf)
+	defer log.SetGlobalLogger(os.Stdout)
+	Define("registry parent error", WithCode("registry_other_error"))
+	assert.Contains(t, buf.String(), `"msg":"errors: type name is already defined with another code",`+
+		`"type":"registry parent error","code":"registry_other_error","defined_code":"registry_parent_error"`)
+}
+
+func TestCode(t *testing.T) {
+	var (
+		auth    = Namespace("auth")
+		billing = Namespace("billing")
+
+		AuthRequiredError   = auth.Define("authentication required", WithCode("required"))
+		AuthExpiredError    = auth.Define("token expired")
+		PaymentRequired     = billing.Define("payment required", WithCode("required"))
+		PaymentUnknownError = billing.Define("payment required (unknown)", WithCode("E1001"))
+	)
+	assert.Equal(t, Code("core.invalid_parameter"), ValidationError.New("illegal code").Code())
+	assert.Equal(t, Code("auth.required"), AuthRequiredError.New("no token").Code())
+	assert.Equal(t, Code("auth.token_expired"), AuthExpiredError.New("expired").Code())
+	assert.Equal(t, Code("billing.required"), PaymentRequired.New("no card").Code())
+	assert.Equal(t, Code("billing.E1001"), PaymentUnknownError.New("unknown").Code())
+	assert.Equal(t, Code("file_i_o_error_2"), defaultCode("File I/O error #2"))
+
+	assert.True(t, AuthRequiredError.Is(AuthRequiredError.New("no token")))
+	assert.False(t, AuthRequiredError.Is(PaymentRequired.New("no card")))
+
+	data, e := Marshal(PaymentRequired.New("no card"))
+	require.NoError(t, e)
+	decoded, e := Unmarshal(data)
+	require.NoError(t, e)
+	assert.True(t, PaymentRequired.Is(decoded))
+	assert.False(t, AuthRequiredError.Is(decoded))
+
+	decoded, e = Unmarshal([]byte(`{"type": "authentication required", "code": "other.required", "msg": "no token"}`))
+	require.NoError(t, e)
+	assert.Equal(t, Code("other.required"), decoded.Code())
+	assert.True(t, RemoteError.Is(decoded))
+	assert.False(t, AuthRequiredError.Is(decoded))
+}
+
+func TestUnmarshal(t *testing.T) {
+	PaymentDeclinedError := Define("payment declined", WithParent(ValidationError))
+	origin := UnexpectedError.Wrap(
+		PaymentDeclinedError.Wrap(errors.New("card expired"), "payment %d failed", 42,
+			"amount", 1200, "rate", 0.5, slog.Group("card", "brand", "visa"), "tags", []string{"a", "b"}),
+		"checkout failed", "userID", 123)
+	data, e := Marshal(origin)
+	require.NoError(t, e)
+
+	decoded, e := Unmarshal(data)
+	require.NoError(t, e)
+	assert.Equal(t, origin.Error(), decoded.Error())
+	assert.Equal(t, origin.StackTrace(), decoded.StackTrace())
+	assert.Equal(t, origin.StackFrames(), decoded.StackFrames())
+	assert.True(t, UnexpectedError.Is(decoded))
+	assert.True(t, PaymentDeclinedError.Is(decoded))
+	assert.True(t, ValidationError.Is(decoded))
+	assert.Equal(t, "[amount=1200 rate=0.5 card=[brand=visa] tags=[a b] userID=123]", fmt.Sprint(decoded.Attrs()))
+	assert.Equal(t, "card expired", Root(decoded).Error())
+
+	var e2 coreError
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "not defined error", "msg": "timeout"}`), &e2))
+	assert.Equal(t, Type("not defined error"), e2.Type())
+	assert.True(t, RemoteError.Is(&e2))
+	assert.False(t, UnexpectedError.Is(&e2))
+	assert.Empty(t, e2.StackFrames())
+
+	decoded, e = Unmarshal([]byte(`{"msg": "timeout"}`))
+	require.NoError(t, e)
+	assert.Equal(t, Type("remote error"), decoded.Type())
+
+	// the local definition wins over the serialized type name
+	decoded, e = Unmarshal([]byte(`{"type": "bad parameter", "code": "core.invalid_parameter", "msg": "x"}`))
+	require.NoError(t, e)
+	assert.Equal(t, Type("invalid parameter"), decoded.Type())
+
+	// core errors behind other errors are kept
+	origin = UnexpectedError.Wrap(fmt.Errorf("handler: %w", ValidationError.New("user %d not found", 123)), "request failed")
+	data, e = Marshal(origin)
+	require.NoError(t, e)
+	decoded, e = Unmarshal(data)
+	require.NoError(t, e)
+	assert.Equal(t, origin.Error(), decoded.Error())
+	assert.True(t, ValidationError.Is(decoded))
+
+	_, e = Unmarshal([]byte(`{"type": "invalid parameter", "msg": "x", "attrs": [1]}`))
+	assert.Error(t, e)
+}
+
+func cutStackLines(t *testing.T, data []byte) string {
+	var value map[string]any
+	require.NoError(t, json.Unmarshal(data, &value))
+	for v := value; v != nil; {
+		if stack, ok := v["stack"].([]any); ok {
+			for _, frame := range stack {
+				frame.(map[string]any)["line"] = 0
+			}
+		}
+		v, _ = v["cause"].(map[string]any)
+	}
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestStackSettings(t *testing.T) {
+	defer currentStackSettings.Store(currentStackSettings.Load())
+
+	newError := func() Error {
+		return func() Error {
+			return ValidationError.New("user name is a required field")
+		}()
+	}
+	frames := newError().StackFrames()
+	require.Len(t, frames, 3)
+	assert.Equal(t, "TestStackSettings.func1.func1", frames[0].Function)
+	assert.Equal(t, "github.com/gotech-labs/core/errors.TestStackSettings.func1.func1", frames[0].Name)
+	assert.Equal(t, currentDir+"/errors_test.go", frames[0].File)
+	assert.Equal(t, "TestStackSettings.func1", frames[1].Function)
+	assert.Equal(t, "TestStackSettings", frames[2].Function)
+
+	SetStackOptions(WithStackDepth(1))
+	require.Len(t, newError().StackFrames(), 1)
+
+	SetStackOptions(WithStackDepth(32), WithFrameFilter(nil), WithTrimPrefixes(currentDir))
+	frames = newError().StackFrames()
+	assert.Equal(t, "errors_test.go", frames[0].File)
+	assert.Equal(t, "tRunner", frames[len(frames)-2].Function)
+	assert.Equal(t, "goexit", frames[len(frames)-1].Function)
+
+	SetStackOptions(WithFrameFilter(ModuleFrames("github.com/gotech-labs/core/errors.TestStackSettings.func1")))
+	frames = newError().StackFrames()
+	require.Len(t, frames, 2)
+	assert.Equal(t, "TestStackSettings.func1.func1", frames[0].Function)
+
+	assert.Empty(t, callers(64, FullStack).StackFrames())
+}
+
+func TestStackMode(t *testing.T) {
+	for _, in := range []struct {
+		name     string
+		mode     StackMode
+		expected []string
+	}{
+		{
+			name: "test - full stack",
+			mode: FullStack,
+			expected: []string{
+				"cheap error: user name is a required field",
+				"    at ${CURRENT_DIR}/errors_test.go:xxx (TestStackMode.func1)",
+				"    at ${CURRENT_DIR}/errors_test.go:xxx (TestStackMode)",
+			},
+		},
+		{
+			name: "test - caller only",
+			mode: CallerOnly,
+			expected: []string{
+				"cheap error: user name is a required field",
+				"    at ${CURRENT_DIR}/errors_test.go:xxx (TestStackMode.func1)",
+			},
+		},
+		{
+			name: "test - no stack",
+			mode: NoStack,
+			expected: []string{
+				"cheap error: user name is a required field",
+			},
+		},
+	} {
+		factory := &errorFactory{name: "cheap error", stackMode: in.mode}
+		err := func() Error {
+			return factory.New("user name is a required field")
+		}()
+		stackTrace := err.StackTrace()
+		require.Len(t, stackTrace, len(in.expected), in.name)
+		for i, trace := range in.expected {
+			expected := strings.ReplaceAll(trace, "${CURRENT_DIR}", currentDir)
+			assert.Equal(t, expected, cutLineNumber(stackTrace[i]), in.name)
+		}
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	for _, in := range []struct {
+		name string
+		mode StackMode
+	}{
+		{name: "full stack", mode: FullStack},
+		{name: "caller only", mode: CallerOnly},
+		{name: "no stack", mode: NoStack},
+	} {
+		factory := Define("benchmark error", WithStackMode(in.mode))
+		b.Run(in.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = factory.New("user name is a required field", "userID", i)
+			}
+		})
+		b.Run(in.name+" with trace", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = factory.New("user name is a required field", "userID", i).StackTrace()
+			}
+		})
 	}
 }
 
@@ -218,3 +974,329 @@ func cutLineNumber(value string) string {
 }
 
 var currentDir, _ = os.Getwd()
+
+func TestRecover(t *testing.T) {
+	var buf strings.Builder
+	log.SetGlobalLogger(&buf)
+	defer log.SetGlobalLogger(os.Stdout)
+
+	panicking := func(v any) (err error) {
+		defer Recover(&err)
+		func() {
+			panic(v)
+		}()
+		return nil
+	}
+	cause := errors.New("boom")
+	for _, in := range []struct {
+		name          string
+		value         any
+		expectedCause error
+	}{
+		{
+			name:          "test - panic with string",
+			value:         "boom",
+			expectedCause: nil,
+		},
+		{
+			name:          "test - panic with error",
+			value:         cause,
+			expectedCause: cause,
+		},
+	} {
+		t.Run(in.name, func(t *testing.T) {
+			err := AsError(panicking(in.value))
+			require.NotNil(t, err)
+			assert.True(t, UnexpectedError.Is(err))
+			assert.Equal(t, "panic recovered", err.Message())
+			assert.Equal(t, []slog.Attr{slog.Any("panic", in.value)}, err.Attrs())
+			assert.Equal(t, in.expectedCause, err.Unwrap())
+			frames := err.StackFrames()
+			require.NotEmpty(t, frames)
+			assert.Equal(t, "TestRecover.func1.1", frames[0].Function)
+		})
+	}
+	assert.Contains(t, buf.String(), `"msg":"panic recovered"`)
+
+	var err error
+	func() {
+		defer RecoverWithContext(context.Background(), &err)
+		var m map[string]int
+		m["key"]++
+	}()
+	require.Error(t, err)
+	assert.Equal(t, "TestRecover.func3", AsError(err).StackFrames()[0].Function)
+
+	err = nil
+	func() {
+		defer Recover(&err)
+	}()
+	assert.NoError(t, err)
+}
+
+func TestGo(t *testing.T) {
+	log.SetGlobalLogger(io.Discard)
+	defer log.SetGlobalLogger(os.Stdout)
+
+	assert.NoError(t, <-Go(func() error { return nil }))
+	assert.Equal(t, io.EOF, <-Go(func() error { return io.EOF }))
+	err := <-Go(func() error { panic("boom") })
+	assert.True(t, UnexpectedError.Is(err))
+	assert.Equal(t, "TestGo.func3", AsError(err).StackFrames()[0].Function)
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	TransientError := Define("transient error", WithRetryable(true))
+	PermanentError := Define("permanent error", WithRetryable(false))
+	for _, in := range []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "test - nil",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "test - golang error",
+			err:      errors.New("mysql open error"),
+			expected: false,
+		},
+		{
+			name:     "test - retryable type",
+			err:      TransientError.New("deadlock found"),
+			expected: true,
+		},
+		{
+			name:     "test - wrapped retryable type",
+			err:      fmt.Errorf("query failed: %w", TransientError.New("deadlock found")),
+			expected: true,
+		},
+		{
+			name:     "test - wrapped by a type without retryability",
+			err:      UnexpectedError.Wrap(TransientError.New("deadlock found"), "query failed"),
+			expected: true,
+		},
+		{
+			name:     "test - not retryable type",
+			err:      PermanentError.Wrap(TransientError.New("deadlock found"), "query failed"),
+			expected: false,
+		},
+		{
+			name:     "test - net timeout",
+			err:      Define("net wrapper").Wrap(&net.OpError{Op: "dial", Err: timeoutError{}}, "dial failed"),
+			expected: true,
+		},
+		{
+			name:     "test - context canceled",
+			err:      context.Canceled,
+			expected: false,
+		},
+		{
+			name:     "test - context deadline exceeded",
+			err:      context.DeadlineExceeded,
+			expected: true,
+		},
+		{
+			name:     "test - multi",
+			err:      Join(TransientError.New("deadlock found"), context.DeadlineExceeded),
+			expected: true,
+		},
+		{
+			name:     "test - partially retryable multi",
+			err:      Join(TransientError.New("deadlock found"), ValidationError.New("bad id")),
+			expected: false,
+		},
+	} {
+		assert.Equal(t, in.expected, IsRetryable(in.err), in.name)
+	}
+}
+
+type requestIDKey struct{}
+
+func TestNewContext(t *testing.T) {
+	defer ctxattr.Register(
+		ctxattr.Value[string]("request_id", requestIDKey{}),
+		func(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+			if ctx.Value(requestIDKey{}) != nil {
+				return append(attrs, slog.String("trace_id", "4bf92f3577b34da6"))
+			}
+			return attrs
+		},
+	)()
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-123")
+	for _, in := range []struct {
+		name     string
+		err      Error
+		expected []slog.Attr
+	}{
+		{
+			name: "test - new error",
+			err:  ValidationError.NewContext(ctx, "user %d not found", 123, "trace_id", "overridden"),
+			expected: []slog.Attr{
+				slog.String("request_id", "req-123"),
+				slog.String("trace_id", "overridden"),
+			},
+		},
+		{
+			name: "test - wrap error",
+			err:  InitializationError.WrapContext(ctx, io.EOF, "database access error", "db", "users"),
+			expected: []slog.Attr{
+				slog.String("request_id", "req-123"),
+				slog.String("trace_id", "4bf92f3577b34da6"),
+				slog.String("db", "users"),
+			},
+		},
+		{
+			name:     "test - context without attributes",
+			err:      ValidationError.NewContext(context.Background(), "user name is a required field"),
+			expected: nil,
+		},
+	} {
+		assert.Equal(t, in.expected, in.err.Attrs(), in.name)
+		assert.Equal(t, "TestNewContext", in.err.StackFrames()[0].Function, in.name)
+	}
+	assert.Equal(t, []slog.Attr{slog.String("db", "users")},
+		OwnAttrs(InitializationError.WrapContext(ctx, ValidationError.New("bad", "id", 1), "database access error", "db", "users")))
+
+	// the attributes are kept when the error is logged elsewhere
+	var buf strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	err := <-Go(func() error {
+		return UnexpectedError.WrapContext(ctx, io.EOF, "read failed")
+	})
+	logger.Error("request failed", "error", err)
+	assert.Contains(t, buf.String(), `"attrs":{"request_id":"req-123","trace_id":"4bf92f3577b34da6"}`)
+}
+
+func TestFingerprint(t *testing.T) {
+	newError := func(id int) Error {
+		return ValidationError.New("user %d not found", id, "id", id)
+	}
+	err1, err2 := newError(1), newError(2)
+	assert.Len(t, err1.Fingerprint(), 16)
+	assert.Equal(t, err1.Fingerprint(), err2.Fingerprint())
+	assert.NotEqual(t, err1.Fingerprint(), ValidationError.New("user %d not found", 1).Fingerprint())
+	assert.NotEqual(t, err1.Fingerprint(), IllegalArgumentError.New("user %d not found", 1).Fingerprint())
+	assert.NotEqual(t, err1.Fingerprint(), UnexpectedError.Wrap(err1, "lookup failed").Fingerprint())
+	assert.Equal(t,
+		UnexpectedError.Wrap(io.EOF, "lookup failed").Fingerprint(),
+		UnexpectedError.Wrap(io.EOF, "lookup failed").Fingerprint())
+	assert.NotEqual(t,
+		UnexpectedError.Wrap(io.EOF, "lookup failed").Fingerprint(),
+		UnexpectedError.Wrap(timeoutError{}, "lookup failed").Fingerprint())
+
+	assert.Equal(t, "", Fingerprint(nil))
+	assert.Equal(t, err1.Fingerprint(), Fingerprint(err1))
+	assert.Equal(t, Fingerprint(io.EOF), Fingerprint(io.EOF))
+	assert.NotEqual(t, Fingerprint(io.EOF), Fingerprint(io.ErrUnexpectedEOF))
+	assert.Equal(t, Fingerprint(fmt.Errorf("lookup: %w", err1)), Fingerprint(fmt.Errorf("lookup: %w", err2)))
+	assert.NotEqual(t, Fingerprint(err1), Fingerprint(fmt.Errorf("lookup: %w", err1)))
+	assert.Equal(t,
+		UnexpectedError.Wrap(fmt.Errorf("lookup: %w", err1), "request failed").Fingerprint(),
+		UnexpectedError.Wrap(fmt.Errorf("lookup: %w", err2), "request failed").Fingerprint())
+
+	defer currentStackSettings.Store(currentStackSettings.Load())
+	// the rendering filter does not change the frames of the fingerprint
+	SetStackOptions(WithFrameFilter(ModuleFrames("example.com/")))
+	assert.Equal(t, err1.Fingerprint(), newError(3).Fingerprint())
+	SetStackOptions(WithFrameFilter(nil))
+	assert.Equal(t, err1.Fingerprint(), newError(3).Fingerprint())
+
+	// the frames of the standard library are not counted
+	decoded := func(names ...string) Error {
+		frames := make(stackFrames, len(names))
+		for i, name := range names {
+			frames[i] = Frame{Name: name}
+		}
+		e := ValidationError.New("bad input").(*coreError)
+		e.stackFrames = symbolizedStack(frames)
+		return e
+	}
+	SetStackOptions(WithFingerprintFrames(1))
+	assert.Equal(t,
+		decoded("net/http.HandlerFunc.ServeHTTP", "github.com/xxx/app.handler", "main.main").Fingerprint(),
+		decoded("github.com/xxx/app.handler", "net/http.(*conn).serve").Fingerprint())
+	assert.NotEqual(t,
+		decoded("net/http.HandlerFunc.ServeHTTP", "github.com/xxx/app.handler").Fingerprint(),
+		decoded("net/http.HandlerFunc.ServeHTTP", "github.com/xxx/app.middleware").Fingerprint())
+
+	SetStackOptions(WithFingerprintFrames(0))
+	assert.Equal(t, newError(1).Fingerprint(), ValidationError.New("user %d not found", 3, "id", 3).Fingerprint())
+}
+
+// tickingClock is a fake clock whose waits end when the test sends a tick.
+type tickingClock struct {
+	*system.FakeClock
+	ticks chan time.Time
+}
+
+func (c tickingClock) After(time.Duration) <-chan time.Time {
+	return c.ticks
+}
+
+func TestAggregator(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := tickingClock{FakeClock: system.NewFakeClock(start), ticks: make(chan time.Time)}
+	aggregator := NewAggregator(time.Minute, WithAggregatorClock(clock))
+	newError := func(id int) Error {
+		return ValidationError.New("user %d not found", id)
+	}
+	first := newError(1)
+	assert.True(t, aggregator.Add(first))
+	clock.Advance(time.Second)
+	assert.False(t, aggregator.Add(newError(2)))
+	clock.Advance(time.Second)
+	assert.True(t, aggregator.Add(io.EOF))
+	clock.Advance(time.Second)
+	assert.False(t, aggregator.Add(newError(3)))
+	assert.False(t, aggregator.Add(nil))
+
+	assert.Equal(t, []Group{
+		{
+			Fingerprint: first.Fingerprint(),
+			Err:         first,
+			Count:       3,
+			FirstSeen:   start,
+			LastSeen:    start.Add(3 * time.Second),
+		},
+		{
+			Fingerprint: Fingerprint(io.EOF),
+			Err:         io.EOF,
+			Count:       1,
+			FirstSeen:   start.Add(2 * time.Second),
+			LastSeen:    start.Add(2 * time.Second),
+		},
+	}, aggregator.Flush())
+	assert.Empty(t, aggregator.Flush())
+	assert.True(t, aggregator.Add(newError(4)))
+
+	var buf strings.Builder
+	log.SetGlobalLogger(&buf)
+	defer log.SetGlobalLogger(os.Stdout)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		aggregator.Run(ctx)
+	}()
+	clock.ticks <- start.Add(time.Minute)
+	// the tick is logged once Run waits again
+	clock.ticks <- start.Add(2 * time.Minute)
+	assert.True(t, aggregator.Add(newError(5)))
+	cancel()
+	<-done
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Contains(t, line, `"msg":"error summary","fingerprint":"`+first.Fingerprint()+`","count":1,`)
+	}
+}
@@ -1,26 +1,89 @@
 package errors
 
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// ErrorFactory creates the errors of a defined type. It is an error itself
+// so that it can be used as the target of errors.Is.
 type ErrorFactory interface {
+	error
+	typeMatcher
 	New(format string, args ...any) Error
+	// NewContext is like New and captures the attributes of ctx extracted
+	// by the extractors registered with log.RegisterContextExtractor.
+	NewContext(ctx context.Context, format string, args ...any) Error
 	Wrap(cause error, format string, args ...any) Error
-	Is(err error) bool
+	WrapContext(ctx context.Context, cause error, format string, args ...any) Error
+	Field(name string, format string, args ...any) Error
+}
+
+// typeMatcher is split from ErrorFactory, and implemented by matcher, so
+// that the factories do not get the Is(error) bool method looked up by
+// errors.Is. That method would mean the opposite: errors.Is(ValidationError,
+// target) would ask whether target is a validation error.
+type typeMatcher interface {
+	// Is reports whether err is of this type or of one of its descendants.
+	Is(err interface{ Error() string }) bool
+}
+
+type matcher struct {
+	factory *errorFactory
+}
+
+func (m matcher) Is(err interface{ Error() string }) bool {
+	return err != nil && errors.Is(err, m.factory)
 }
 
 type errorFactory struct {
-	name Type
+	matcher
+	name       Type
+	code       Code
+	origin     origin
+	parent     *errorFactory
+	status     Status
+	stackMode  StackMode
+	messageKey string
+	// retryableSet tells whether status.Retryable was set by WithRetryable.
+	retryableSet bool
+}
+
+type option func(*errorFactory)
+
+func WithParent(parent ErrorFactory) option {
+	return func(factory *errorFactory) {
+		factory.parent = parent.(*errorFactory)
+	}
+}
+
+func (ef *errorFactory) Error() string {
+	return string(ef.name)
 }
 
 func (ef *errorFactory) New(format string, args ...any) Error {
-	return newError(ef.name, nil, format, args...)
+	return newError(ef, nil, format, args...)
 }
 
 func (ef *errorFactory) Wrap(cause error, format string, args ...any) Error {
-	return newError(ef.name, cause, format, args...)
+	return newError(ef, cause, format, args...)
+}
+
+// Field creates an error about the named field, see Fields.
+func (ef *errorFactory) Field(name string, format string, args ...any) Error {
+	e := newError(ef, nil, format, args...)
+	e.field = name
+	e.attrs = append([]slog.Attr{slog.String(fieldKey, name)}, e.attrs...)
+	return e
 }
 
-func (ef *errorFactory) Is(err error) bool {
-	if e := AsError(err); e != nil {
-		return ef.name == e.Type()
+// isA reports whether ef is target or one of its descendants.
+func (ef *errorFactory) isA(target *errorFactory) bool {
+	for f := ef; f != nil; f = f.parent {
+		if f.code == target.code {
+			return true
+		}
 	}
 	return false
 }
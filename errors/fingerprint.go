@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Fingerprint identifies the errors of the same kind raised at the same
+// place, for grouping them. It is computed from the code, the message
+// template before interpolation and the top frames of the stack out of
+// the standard library, see WithFingerprintFrames, along with the
+// fingerprint of the cause.
+func (e *coreError) Fingerprint() string {
+	h := sha256.New()
+	e.writeFingerprint(h)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+func (e *coreError) writeFingerprint(h hash.Hash) {
+	template := e.template
+	if template == "" {
+		// decoded errors have no template
+		template = e.message
+	}
+	fmt.Fprintf(h, "%s\x00%s\x00", e.Code(), template)
+	// the line numbers are left out to survive unrelated edits
+	for _, name := range e.stackFrames.moduleFrameNames(currentStackSettings.Load().fingerprintFrames) {
+		fmt.Fprintf(h, "%s\x00", name)
+	}
+	switch cause := e.cause.(type) {
+	case nil:
+	case *coreError:
+		_, _ = io.WriteString(h, "\x01")
+		cause.writeFingerprint(h)
+	default:
+		fmt.Fprintf(h, "\x01%T", cause)
+		if next, ok := AsError(cause).(*coreError); ok {
+			_, _ = io.WriteString(h, "\x01")
+			next.writeFingerprint(h)
+		}
+	}
+}
+
+// Fingerprint returns the fingerprint of err. The wrappers of core errors,
+// like those of fmt.Errorf, are identified by their type and the
+// fingerprint of the core error, the other errors by their type and
+// message.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	if e, ok := err.(Error); ok {
+		return e.Fingerprint()
+	}
+	h := sha256.New()
+	if e := AsError(err); e != nil {
+		fmt.Fprintf(h, "%T\x00%s", err, e.Fingerprint())
+	} else {
+		fmt.Fprintf(h, "%T\x00%s", err, err.Error())
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
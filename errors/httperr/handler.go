@@ -0,0 +1,91 @@
+package httperr
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/gotech-labs/core/errors"
+)
+
+// Write writes err to w as a problem+json response.
+func Write(w http.ResponseWriter, r *http.Request, err error, options ...option) {
+	write(w, r, err, newSettings(options))
+}
+
+func write(w http.ResponseWriter, r *http.Request, err error, settings *settings) {
+	problem := newProblem(err, r, settings)
+	body, e := json.Marshal(problem)
+	if e != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	_, _ = w.Write(body)
+}
+
+// HandlerFunc is a http handler returning an error, which is written to
+// the response as a problem+json document.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		write(w, r, err, defaultSettings)
+	}
+}
+
+func Handler(f HandlerFunc, options ...option) http.Handler {
+	settings := newSettings(options)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := f(w, r); err != nil {
+			write(w, r, err, settings)
+		}
+	})
+}
+
+// Recoverer is a middleware converting the panics of next into an
+// UnexpectedError, which is logged and written as a 500 problem+json
+// response unless next already wrote the response headers.
+func Recoverer(next http.Handler, options ...option) http.Handler {
+	settings := newSettings(options)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		rw := &responseWriter{ResponseWriter: w}
+		func() {
+			defer errors.RecoverWithContext(r.Context(), &err)
+			next.ServeHTTP(rw, r)
+		}()
+		if err == nil {
+			return
+		}
+		if stderrors.Is(err, http.ErrAbortHandler) {
+			panic(http.ErrAbortHandler)
+		}
+		if !rw.wroteHeader {
+			write(w, r, err, settings)
+		}
+	})
+}
+
+// responseWriter tells whether the response headers were written.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,201 @@
+package httperr_test
+
+import (
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gotech-labs/core/errors"
+	. "github.com/gotech-labs/core/errors/httperr"
+	"github.com/gotech-labs/core/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	ConflictError := errors.Define("conflict",
+		errors.WithHTTPStatus(http.StatusConflict),
+		errors.WithPublicMessage("the resource was modified concurrently"))
+	for _, in := range []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "test - no error",
+			err:            nil,
+			expectedStatus: http.StatusOK,
+			expectedBody:   ``,
+		},
+		{
+			name:           "test - golang error",
+			err:            stderrors.New("mysql open error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: `{
+				"type": "about:blank",
+				"title": "Internal Server Error",
+				"status": 500,
+				"instance": "/users/123?verbose=1"
+			}`,
+		},
+		{
+			name:           "test - client error",
+			err:            errors.ValidationError.New("user name is a required field", "userID", 123),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{
+				"type": "urn:problem-type:core.invalid_parameter",
+				"title": "invalid parameter",
+				"status": 400,
+				"detail": "user name is a required field",
+				"instance": "/users/123?verbose=1",
+				"userID": 123
+			}`,
+		},
+		{
+			name: "test - client error with cause",
+			err: errors.ValidationError.Wrap(
+				errors.UnexpectedError.New("query failed", "table", "users"), "user name is invalid", "userID", 123),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{
+				"type": "urn:problem-type:core.invalid_parameter",
+				"title": "invalid parameter",
+				"status": 400,
+				"detail": "user name is invalid",
+				"instance": "/users/123?verbose=1",
+				"userID": 123
+			}`,
+		},
+		{
+			name: "test - field errors",
+			err: errors.Join(
+				errors.ValidationError.Field("email", "must not be empty"),
+				errors.ValidationError.Field("name", "must not be longer than %d characters", 32)),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{
+				"type": "urn:problem-type:core.invalid_parameter",
+				"title": "invalid parameter",
+				"status": 400,
+				"detail": "2 errors occurred",
+				"instance": "/users/123?verbose=1",
+				"fields": {
+					"email": ["must not be empty"],
+					"name": ["must not be longer than 32 characters"]
+				}
+			}`,
+		},
+		{
+			name:           "test - public message",
+			err:            ConflictError.New("version 3 != 4", "status", "overwritten"),
+			expectedStatus: http.StatusConflict,
+			expectedBody: `{
+				"type": "urn:problem-type:conflict",
+				"title": "conflict",
+				"status": 409,
+				"detail": "the resource was modified concurrently",
+				"instance": "/users/123?verbose=1"
+			}`,
+		},
+		{
+			name:           "test - server error",
+			err:            errors.UnexpectedError.Wrap(stderrors.New("mysql open error"), "database access error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: `{
+				"type": "urn:problem-type:core.unexpected_error",
+				"title": "unexpected error",
+				"status": 500,
+				"instance": "/users/123?verbose=1"
+			}`,
+		},
+	} {
+		handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+			return in.err
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/123?verbose=1", nil))
+		assert.Equal(t, in.expectedStatus, rec.Code, in.name)
+		if in.expectedBody == "" {
+			assert.Empty(t, rec.Body.String(), in.name)
+			continue
+		}
+		assert.Equal(t, ContentType, rec.Header().Get("Content-Type"), in.name)
+		assert.JSONEq(t, in.expectedBody, rec.Body.String(), in.name)
+	}
+}
+
+func TestDebug(t *testing.T) {
+	err := errors.UnexpectedError.Wrap(stderrors.New("mysql open error"), "database access error")
+	problem := NewProblem(err, nil, WithDebug(true), WithTypeBaseURI("https://example.com/problems/"))
+	assert.Equal(t, "https://example.com/problems/core.unexpected_error", problem.Type)
+	assert.Equal(t, "database access error", problem.Detail)
+	assert.Empty(t, problem.Instance)
+	assert.Equal(t, "mysql open error", problem.Extensions["cause"])
+	stackTrace, ok := problem.Extensions["stack_trace"].(errors.StackTrace)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(stackTrace[len(stackTrace)-1], "    at "))
+}
+
+func TestHandlerFunc(t *testing.T) {
+	var handler http.Handler = HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.AuthenticationRequiredError.New("token expired")
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/me", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.JSONEq(t, `{
+		"type": "urn:problem-type:core.authentication_required",
+		"title": "authentication required",
+		"status": 401,
+		"detail": "token expired",
+		"instance": "/me"
+	}`, rec.Body.String())
+}
+
+func TestRecoverer(t *testing.T) {
+	var buf strings.Builder
+	log.SetGlobalLogger(&buf)
+	defer log.SetGlobalLogger(os.Stdout)
+
+	handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/panic":
+			panic("boom")
+		case "/partial":
+			w.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{
+		"type": "urn:problem-type:core.unexpected_error",
+		"title": "unexpected error",
+		"status": 500,
+		"instance": "/panic"
+	}`, rec.Body.String())
+
+	// the problem is not written once the headers are
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/partial", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, 2, strings.Count(buf.String(), `"level":"ERROR","msg":"panic recovered"`))
+
+	abort := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.Equal(t, 2, strings.Count(buf.String(), `"level":"ERROR","msg":"panic recovered"`))
+}
@@ -0,0 +1,131 @@
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gotech-labs/core/errors"
+	"github.com/gotech-labs/core/internal/attrmap"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is a RFC 7807 problem details document.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+var reservedMembers = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		if !reservedMembers[k] {
+			members[k] = v
+		}
+	}
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+func NewProblem(err error, r *http.Request, options ...option) *Problem {
+	return newProblem(err, r, newSettings(options))
+}
+
+func newProblem(err error, r *http.Request, settings *settings) *Problem {
+	status := errors.StatusOf(err)
+	problem := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status.HTTPStatus),
+		Status: status.HTTPStatus,
+	}
+	if r != nil {
+		problem.Instance = r.URL.RequestURI()
+	}
+	e := errors.AsError(err)
+	if e == nil {
+		if settings.debug {
+			problem.Detail = err.Error()
+		}
+		return problem
+	}
+	problem.Type = settings.typeBaseURI + string(e.Code())
+	problem.Title = string(e.Type())
+	if multi := multiOf(err); multi != nil {
+		// the errors of a Multi are only reported by their fields
+		problem.Detail = fmt.Sprintf("%d errors occurred", len(multi.Errors()))
+		if fields := multi.Fields(); len(fields) > 0 {
+			problem.Extensions = map[string]any{"fields": fields}
+		}
+		if settings.debug {
+			if problem.Extensions == nil {
+				problem.Extensions = make(map[string]any, 1)
+			}
+			problem.Extensions["stack_trace"] = multi.StackTrace()
+		}
+		return problem
+	}
+	switch {
+	case settings.debug || (status.PublicMessage == "" && status.HTTPStatus < 500):
+		problem.Detail = e.Message()
+	case status.PublicMessage != "":
+		problem.Detail = status.PublicMessage
+	}
+	switch {
+	case settings.debug:
+		problem.Extensions = attrmap.FromAttrs(e.Attrs())
+	case status.HTTPStatus < 500:
+		// the attributes of the causes, the context and the server errors
+		// may reveal internals
+		problem.Extensions = attrmap.FromAttrs(errors.OwnAttrs(err))
+	}
+	if fields := errors.Fields(err); len(fields) > 0 {
+		if problem.Extensions == nil {
+			problem.Extensions = make(map[string]any, 1)
+		}
+		problem.Extensions["fields"] = fields
+	}
+	if settings.debug {
+		if problem.Extensions == nil {
+			problem.Extensions = make(map[string]any, 2)
+		}
+		if cause := e.Unwrap(); cause != nil {
+			problem.Extensions["cause"] = cause.Error()
+		}
+		problem.Extensions["stack_trace"] = e.StackTrace()
+	}
+	return problem
+}
+
+// multiOf returns the *errors.Multi of err unless a core error wraps it.
+func multiOf(err error) *errors.Multi {
+	for _, cause := range errors.Chain(err) {
+		switch x := cause.(type) {
+		case *errors.Multi:
+			return x
+		case errors.Error:
+			return nil
+		}
+	}
+	return nil
+}
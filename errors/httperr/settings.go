@@ -0,0 +1,42 @@
+package httperr
+
+var defaultSettings = &settings{
+	typeBaseURI: "urn:problem-type:",
+}
+
+type settings struct {
+	typeBaseURI string
+	debug       bool
+}
+
+type option func(*settings)
+
+// Setup changes the settings used when no options are given.
+func Setup(options ...option) {
+	defaultSettings = newSettings(options)
+}
+
+func newSettings(options []option) *settings {
+	if len(options) == 0 {
+		return defaultSettings
+	}
+	settings := *defaultSettings
+	for _, option := range options {
+		option(&settings)
+	}
+	return &settings
+}
+
+func WithTypeBaseURI(uri string) func(options *settings) {
+	return func(settings *settings) {
+		settings.typeBaseURI = uri
+	}
+}
+
+// WithDebug exposes the internal details of the errors, such as the cause
+// and the stack trace, in the problem documents.
+func WithDebug(debug bool) func(options *settings) {
+	return func(settings *settings) {
+		settings.debug = debug
+	}
+}
@@ -0,0 +1,139 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const fieldKey = "field"
+
+// Multi is an error aggregating several failures.
+type Multi struct {
+	errs []error
+}
+
+// Append appends errs to err. The result is nil when there is no error
+// at all, otherwise a *Multi.
+func Append(err error, errs ...error) error {
+	var m *Multi
+	switch x := err.(type) {
+	case nil:
+		m = &Multi{}
+	case *Multi:
+		if x == nil {
+			m = &Multi{}
+			break
+		}
+		m = &Multi{errs: append([]error(nil), x.errs...)}
+	default:
+		m = &Multi{errs: []error{err}}
+	}
+	for _, e := range errs {
+		if e == nil {
+			continue
+		}
+		if other, ok := e.(*Multi); ok {
+			if other != nil {
+				m.errs = append(m.errs, other.errs...)
+			}
+			continue
+		}
+		m.errs = append(m.errs, e)
+	}
+	if len(m.errs) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Join returns a *Multi holding the non-nil errs, or nil if there is none.
+func Join(errs ...error) error {
+	return Append(nil, errs...)
+}
+
+func (m *Multi) Errors() []error {
+	return m.errs
+}
+
+func (m *Multi) Unwrap() []error {
+	return m.errs
+}
+
+func (m *Multi) Error() string {
+	if len(m.errs) == 1 {
+		return m.errs[0].Error()
+	}
+	messages := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(m.errs), strings.Join(messages, "; "))
+}
+
+func (m *Multi) StackTrace() StackTrace {
+	var traceLines []string
+	for _, err := range m.errs {
+		if e := AsError(err); e != nil {
+			traceLines = append(traceLines, e.StackTrace()...)
+			continue
+		}
+		traceLines = append(traceLines, fmt.Sprintf("Caused by: %v", err))
+	}
+	return StackTrace(traceLines)
+}
+
+func (m *Multi) MarshalJSON() ([]byte, error) {
+	values := make([]any, len(m.errs))
+	for i, err := range m.errs {
+		if marshaler, ok := err.(json.Marshaler); ok {
+			values[i] = marshaler
+			continue
+		}
+		values[i] = map[string]string{"msg": err.Error()}
+	}
+	return json.Marshal(values)
+}
+
+// Fields returns the messages of the field errors in err, keyed by field name.
+func (m *Multi) Fields() map[string][]string {
+	return Fields(m)
+}
+
+// Fields returns the messages of the field errors found in the error tree
+// of err, keyed by field name.
+func Fields(err error) map[string][]string {
+	var fields map[string][]string
+	walk(err, func(err error) bool {
+		e, ok := err.(*coreError)
+		if !ok {
+			return true
+		}
+		if e.field == "" {
+			return true
+		}
+		if fields == nil {
+			fields = make(map[string][]string)
+		}
+		fields[e.field] = append(fields[e.field], e.message)
+		return false
+	})
+	return fields
+}
+
+// walk calls fn for each error in the tree of err in depth-first order.
+// The causes of an error are skipped when fn returns false.
+func walk(err error, fn func(error) bool) {
+	if err == nil || !fn(err) {
+		return
+	}
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			walk(e, fn)
+		}
+	default:
+		walk(errors.Unwrap(err), fn)
+	}
+}
@@ -0,0 +1,124 @@
+package errors
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gotech-labs/core/internal/pkgpath"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiFaint  = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// minElidedFrames is the length from which the runs of standard library
+// frames are elided.
+const minElidedFrames = 3
+
+// WithColor enables the ANSI colors in the "%+v" rendering of the errors.
+func WithColor(enabled bool) stackOption {
+	return func(settings *stackSettings) {
+		settings.color = enabled
+	}
+}
+
+// WithSourceLines shows the source lines around the top frame of each
+// error in the "%+v" rendering of the errors, when the files are
+// readable. 0 disables the snippets.
+func WithSourceLines(n int) stackOption {
+	return func(settings *stackSettings) {
+		settings.sourceLines = max(n, 0)
+	}
+}
+
+// prettyPrinter renders an error and its causes for humans, from the
+// outermost error to the root cause, like the Java stack traces.
+type prettyPrinter struct {
+	w        io.Writer
+	settings *stackSettings
+}
+
+func (p *prettyPrinter) print(e *coreError) {
+	p.printf(ansiBold+ansiRed, "%s", e.name)
+	fmt.Fprintf(p.w, ": %s\n", e.message)
+	p.printFrames(e.StackFrames())
+	for cause := e.cause; cause != nil; {
+		p.printf(ansiYellow, "Caused by: ")
+		next, ok := AsError(cause).(*coreError)
+		if !ok {
+			fmt.Fprintf(p.w, "%v\n", cause)
+			return
+		}
+		p.printf(ansiBold+ansiRed, "%s", next.name)
+		fmt.Fprintf(p.w, ": %s\n", next.message)
+		p.printFrames(next.StackFrames())
+		cause = next.cause
+	}
+}
+
+func (p *prettyPrinter) printFrames(frames stackFrames) {
+	for i := 0; i < len(frames); i++ {
+		if run := stdlibRun(frames[i:]); run >= minElidedFrames {
+			p.printf(ansiFaint, "    ... %d standard library frames\n", run)
+			i += run - 1
+			continue
+		}
+		frame := frames[i]
+		fmt.Fprint(p.w, "    at ")
+		p.printf(ansiFaint, "%s:%d", frame.File, frame.Line)
+		fmt.Fprint(p.w, " (")
+		p.printf(ansiCyan, "%s", frame.Function)
+		fmt.Fprint(p.w, ")\n")
+		if i == 0 && p.settings.sourceLines > 0 {
+			p.printSource(frame)
+		}
+	}
+}
+
+func (p *prettyPrinter) printSource(frame Frame) {
+	file, err := os.Open(frame.File)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	first, last := frame.Line-p.settings.sourceLines, frame.Line+p.settings.sourceLines
+	width := len(fmt.Sprint(last))
+	scanner := bufio.NewScanner(file)
+	for n := 1; n <= last && scanner.Scan(); n++ {
+		if n < first {
+			continue
+		}
+		line := strings.ReplaceAll(scanner.Text(), "\t", "    ")
+		if n == frame.Line {
+			p.printf(ansiBold, "      > %*d | %s\n", width, n, line)
+		} else {
+			p.printf(ansiFaint, "        %*d | %s\n", width, n, line)
+		}
+	}
+}
+
+func (p *prettyPrinter) printf(color string, format string, args ...any) {
+	if p.settings.color {
+		fmt.Fprint(p.w, color)
+		defer fmt.Fprint(p.w, ansiReset)
+	}
+	fmt.Fprintf(p.w, format, args...)
+}
+
+// stdlibRun returns the number of leading frames of the standard library.
+func stdlibRun(frames stackFrames) int {
+	for i, frame := range frames {
+		if !pkgpath.IsStdlib(pkgpath.Of(frame.Name)) {
+			return i
+		}
+	}
+	return len(frames)
+}
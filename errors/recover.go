@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"github.com/gotech-labs/core/log"
+)
+
+// panicKey is the attribute key of the recovered panic value.
+const panicKey = "panic"
+
+// Recover converts a panic into an UnexpectedError which is logged and
+// assigned to *err. It must be deferred directly:
+//
+//	defer errors.Recover(&err)
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		*err = recovered(context.Background(), r)
+	}
+}
+
+// RecoverWithContext is like Recover but logs with ctx.
+func RecoverWithContext(ctx context.Context, err *error) {
+	if r := recover(); r != nil {
+		*err = recovered(ctx, r)
+	}
+}
+
+// Go calls fn in a new goroutine and sends its error, or the error of its
+// panic, to the returned channel.
+func Go(fn func() error) <-chan error {
+	errc := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			errc <- err
+			close(errc)
+		}()
+		defer Recover(&err)
+		err = fn()
+	}()
+	return errc
+}
+
+func recovered(ctx context.Context, r any) Error {
+	factory := UnexpectedError.(*errorFactory)
+	cause, _ := r.(error)
+	err := &coreError{
+		name:        factory.name,
+		factory:     factory,
+		cause:       cause,
+		template:    "panic recovered",
+		message:     "panic recovered",
+		attrs:       argsToAttrs([]any{panicKey, r}),
+		stackFrames: panicStack(factory.stackMode),
+	}
+	if r != http.ErrAbortHandler {
+		// like net/http, the aborted handlers are not logged
+		log.ErrorWithContext(ctx, err.message, "error", err)
+	}
+	return err
+}
+
+// panicStack captures the stack of the panic site, which is found below
+// the runtime.gopanic frame.
+func panicStack(mode StackMode) *stack {
+	if mode == NoStack {
+		return nil
+	}
+	depth := 1
+	if mode != CallerOnly {
+		depth = currentStackSettings.Load().depth
+	}
+	pcs := make([]uintptr, depth+32)
+	n := runtime.Callers(3, pcs)
+	pcs = pcs[:n]
+	for i, pc := range pcs {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			pcs = pcs[i+1:]
+			break
+		}
+	}
+	if mode == CallerOnly {
+		// skip the runtime frames of the panics raised by the runtime
+		for len(pcs) > 1 {
+			fn := runtime.FuncForPC(pcs[0] - 1)
+			if fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+				break
+			}
+			pcs = pcs[1:]
+		}
+	}
+	return &stack{pcs: pcs[:min(depth, len(pcs))]}
+}
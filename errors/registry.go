@@ -0,0 +1,119 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gotech-labs/core/internal/pkgpath"
+	"github.com/gotech-labs/core/log"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[Code]*errorFactory)
+)
+
+// Definition describes a defined error type.
+type Definition struct {
+	Name Type
+	Code Code
+	// Package is the import path of the package defining the type.
+	Package string
+	// Parent is the name of the parent type, if any.
+	Parent     Type
+	Status     Status
+	MessageKey string
+}
+
+// Types returns the definitions of all the defined error types sorted by code.
+func Types() []Definition {
+	registryMu.RLock()
+	definitions := make([]Definition, 0, len(registry))
+	for _, factory := range registry {
+		definitions = append(definitions, factory.definition())
+	}
+	registryMu.RUnlock()
+	slices.SortFunc(definitions, func(a, b Definition) int {
+		return strings.Compare(string(a.Code), string(b.Code))
+	})
+	return definitions
+}
+
+// Lookup returns the definition of the error type of the given code.
+func Lookup(code Code) (Definition, bool) {
+	factory := lookup(code)
+	if factory == nil {
+		return Definition{}, false
+	}
+	return factory.definition(), true
+}
+
+func (ef *errorFactory) definition() Definition {
+	definition := Definition{
+		Name:       ef.name,
+		Code:       ef.code,
+		Package:    ef.origin.pkg,
+		Status:     ef.status,
+		MessageKey: ef.messageKey,
+	}
+	if ef.parent != nil {
+		definition.Parent = ef.parent.name
+	}
+	return definition
+}
+
+type origin struct {
+	pkg  string
+	file string
+	line int
+}
+
+func (o origin) String() string {
+	return fmt.Sprintf("%s (%s:%d)", o.pkg, o.file, o.line)
+}
+
+func callerOrigin(skip int) origin {
+	pc, file, line, _ := runtime.Caller(skip)
+	return origin{
+		pkg:  pkgpath.Of(runtime.FuncForPC(pc).Name()),
+		file: file,
+		line: line,
+	}
+}
+
+// register records factory. Defining a type of the same code again is
+// only allowed from the same place, like a test run several times, and
+// defining a type of the same name with another code is warned.
+func register(factory *errorFactory) {
+	registryMu.Lock()
+	if defined, exists := registry[factory.code]; exists && defined.origin != factory.origin {
+		registryMu.Unlock()
+		panic(fmt.Sprintf("errors: code %q of type %q is already defined in %s",
+			factory.code, factory.name, defined.origin))
+	}
+	var sameName *errorFactory
+	for _, defined := range registry {
+		if defined.name == factory.name && defined.code != factory.code {
+			sameName = defined
+			break
+		}
+	}
+	registry[factory.code] = factory
+	registryMu.Unlock()
+	if sameName != nil {
+		log.Warn("errors: type name is already defined with another code",
+			"type", factory.name,
+			"code", factory.code,
+			"defined_code", sameName.code,
+			"defined_in", sameName.origin.String())
+	}
+}
+
+func lookup(code Code) *errorFactory {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[code]
+}
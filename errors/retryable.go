@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"context"
+	"net"
+)
+
+// IsRetryable reports whether the operation failing with err may succeed
+// when retried. The chain is walked from the outermost error: the first
+// error type defined with WithRetryable decides, timeouts of net.Error and
+// context.DeadlineExceeded are retryable, context.Canceled is not, and
+// multiple errors are retryable if all of them are.
+func IsRetryable(err error) bool {
+	for _, cause := range Chain(err) {
+		switch cause {
+		case context.Canceled:
+			return false
+		case context.DeadlineExceeded:
+			return true
+		}
+		switch e := cause.(type) {
+		case interface{ Unwrap() []error }:
+			errs := e.Unwrap()
+			for _, err := range errs {
+				if !IsRetryable(err) {
+					return false
+				}
+			}
+			return len(errs) > 0
+		case *coreError:
+			if e.factory == nil {
+				continue
+			}
+			if retryable, ok := e.factory.definedRetryable(); ok {
+				return retryable
+			}
+		case net.Error:
+			if e.Timeout() {
+				return true
+			}
+		}
+		if temporary, ok := cause.(interface{ Temporary() bool }); ok && temporary.Temporary() {
+			return true
+		}
+	}
+	return false
+}
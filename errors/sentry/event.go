@@ -0,0 +1,122 @@
+// Package sentry exports the errors to Sentry, or to any service accepting
+// the Sentry envelopes.
+package sentry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gotech-labs/core/errors"
+	"github.com/gotech-labs/core/internal/attrmap"
+	"github.com/gotech-labs/core/internal/pkgpath"
+)
+
+// Event is a Sentry error event.
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Exception   Exceptions        `json:"exception"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+}
+
+type Exceptions struct {
+	// Values lists the cause chain from the root cause to the outermost
+	// error, as expected by Sentry.
+	Values []Exception `json:"values"`
+}
+
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+type Stacktrace struct {
+	// Frames lists the frames from the outermost call to the innermost one.
+	Frames []Frame `json:"frames"`
+}
+
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// NewEvent converts err into an event.
+func NewEvent(err error, timestamp time.Time) *Event {
+	return newEvent(err, timestamp, defaultSettings)
+}
+
+func newEvent(err error, timestamp time.Time, settings *settings) *Event {
+	event := &Event{
+		EventID:     newEventID(),
+		Timestamp:   timestamp.UTC(),
+		Platform:    "go",
+		Level:       "error",
+		Environment: settings.environment,
+		Release:     settings.release,
+		ServerName:  settings.serverName,
+	}
+	e := errors.AsError(err)
+	if e == nil {
+		event.Exception.Values = []Exception{{Type: fmt.Sprintf("%T", err), Value: err.Error()}}
+		return event
+	}
+	event.Tags = map[string]string{"error.code": string(e.Code())}
+	event.Extra = attrmap.FromAttrs(e.Attrs())
+	event.Fingerprint = []string{e.Fingerprint()}
+	for e != nil {
+		event.Exception.Values = append(event.Exception.Values, Exception{
+			Type:       string(e.Type()),
+			Value:      e.Message(),
+			Module:     string(e.Code()),
+			Stacktrace: newStacktrace(e.StackFrames(), settings),
+		})
+		cause := e.Unwrap()
+		if e = errors.AsError(cause); e == nil && cause != nil {
+			event.Exception.Values = append(event.Exception.Values, Exception{
+				Type:  fmt.Sprintf("%T", cause),
+				Value: cause.Error(),
+			})
+		}
+	}
+	slices.Reverse(event.Exception.Values)
+	return event
+}
+
+func newStacktrace(frames []errors.Frame, settings *settings) *Stacktrace {
+	if len(frames) == 0 {
+		return nil
+	}
+	stacktrace := &Stacktrace{Frames: make([]Frame, len(frames))}
+	for i, frame := range frames {
+		module := pkgpath.Of(frame.Name)
+		stacktrace.Frames[len(frames)-1-i] = Frame{
+			Function: frame.Function,
+			Module:   module,
+			Filename: frame.File,
+			Lineno:   frame.Line,
+			InApp:    settings.inApp(module),
+		}
+	}
+	return stacktrace
+}
+
+func newEventID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
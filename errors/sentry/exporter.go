@@ -0,0 +1,286 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotech-labs/core/errors"
+)
+
+const (
+	userAgent         = "gotech-labs-core/1.0"
+	defaultRetryAfter = time.Minute
+)
+
+var (
+	// InvalidDSNError is returned for the malformed DSNs.
+	InvalidDSNError = errors.Namespace("sentry").Define("invalid dsn")
+	// TransportError is returned when events could not be delivered, in
+	// which case they are kept queued.
+	TransportError = errors.Namespace("sentry").Define("transport error",
+		errors.WithRetryable(true))
+)
+
+// Exporter ships the errors to Sentry in the background. The events are
+// queued in memory while Sentry can't be reached or rate limits them.
+type Exporter struct {
+	dsn      string
+	endpoint string
+	auth     string
+	settings *settings
+	limiter  *limiter
+
+	mu         sync.Mutex
+	queue      []*Event
+	dropped    int
+	retryAfter time.Time
+
+	flushMu sync.Mutex
+	wakeup  chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// New creates an exporter sending the events to the project of dsn, like
+// "https://public-key@sentry.example.com/42", and starts its background
+// loop.
+func New(dsn string, options ...option) (*Exporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, InvalidDSNError.Wrap(err, "cannot parse dsn")
+	}
+	project := strings.TrimPrefix(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || u.Host == "" || project == "" {
+		return nil, InvalidDSNError.New("dsn %q lacks the public key, the host or the project", dsn)
+	}
+	settings := newSettings(options)
+	exporter := &Exporter{
+		dsn:      dsn,
+		endpoint: fmt.Sprintf("%s://%s/api/%s/envelope/", u.Scheme, u.Host, project),
+		auth: fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s",
+			userAgent, u.User.Username()),
+		settings: settings,
+		limiter:  newLimiter(settings.rateLimit, settings.burst, settings.clock.Now()),
+		wakeup:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go exporter.loop()
+	return exporter, nil
+}
+
+// ExportError queues err to be sent with the next batch. It implements
+// log.ErrorExporter. The errors exported after Close are dropped.
+func (e *Exporter) ExportError(_ context.Context, err error) {
+	if err == nil {
+		return
+	}
+	select {
+	case <-e.done:
+		e.mu.Lock()
+		e.dropped++
+		e.mu.Unlock()
+		return
+	default:
+	}
+	now := e.settings.clock.Now()
+	if !e.limiter.allow(now) {
+		e.mu.Lock()
+		e.dropped++
+		e.mu.Unlock()
+		return
+	}
+	event := newEvent(err, now, e.settings)
+	e.mu.Lock()
+	if len(e.queue) >= e.settings.queueSize {
+		e.queue = e.queue[1:]
+		e.dropped++
+	}
+	e.queue = append(e.queue, event)
+	full := len(e.queue) >= e.settings.batchSize
+	e.mu.Unlock()
+	if full {
+		select {
+		case e.wakeup <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Dropped returns the number of events dropped by the rate limit, because
+// the queue was full or because the exporter was closed.
+func (e *Exporter) Dropped() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dropped
+}
+
+// Pending returns the number of queued events.
+func (e *Exporter) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.queue)
+}
+
+// Flush sends the queued events. The events which could not be delivered
+// stay queued for the next flush.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.flushMu.Lock()
+	defer e.flushMu.Unlock()
+
+	e.mu.Lock()
+	if e.settings.clock.Now().Before(e.retryAfter) {
+		e.mu.Unlock()
+		return nil
+	}
+	events := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	for i, event := range events {
+		retryAfter, err := e.send(ctx, event)
+		if err != nil {
+			e.mu.Lock()
+			// requeue the undelivered events before the new ones
+			e.queue = append(events[i:len(events):len(events)], e.queue...)
+			if overflow := len(e.queue) - e.settings.queueSize; overflow > 0 {
+				e.queue = e.queue[overflow:]
+				e.dropped += overflow
+			}
+			if retryAfter > 0 {
+				e.retryAfter = e.settings.clock.Now().Add(retryAfter)
+			}
+			e.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the background loop and flushes the queued events.
+func (e *Exporter) Close(ctx context.Context) error {
+	select {
+	case <-e.done:
+	default:
+		close(e.done)
+	}
+	<-e.stopped
+	return e.Flush(ctx)
+}
+
+func (e *Exporter) loop() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(e.settings.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+		case <-e.wakeup:
+		}
+		_ = e.Flush(context.Background())
+	}
+}
+
+// send posts event and returns how long to wait before the next attempt
+// when Sentry rate limits it.
+func (e *Exporter) send(ctx context.Context, event *Event) (time.Duration, error) {
+	body, err := e.envelope(event)
+	if err != nil {
+		// an event which can't be encoded is dropped
+		return 0, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, TransportError.Wrap(err, "cannot create request")
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Sentry-Auth", e.auth)
+	resp, err := e.settings.client.Do(req)
+	if err != nil {
+		return 0, TransportError.Wrap(err, "cannot send event %s", event.EventID)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return retryAfter(resp.Header), TransportError.New("event %s was rate limited", event.EventID)
+	case resp.StatusCode >= 500:
+		return 0, TransportError.New("event %s was rejected with status %d", event.EventID, resp.StatusCode)
+	}
+	// the events rejected as invalid are dropped
+	return 0, nil
+}
+
+// envelope encodes event as a Sentry envelope.
+func (e *Exporter) envelope(event *Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if err := enc.Encode(map[string]any{
+		"event_id": event.EventID,
+		"sent_at":  e.settings.clock.Now().UTC(),
+		"dsn":      e.dsn,
+	}); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(map[string]any{
+		"type":         "event",
+		"length":       len(payload),
+		"content_type": "application/json",
+	}); err != nil {
+		return nil, err
+	}
+	buf.Write(payload)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func retryAfter(header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultRetryAfter
+}
+
+// limiter is a token bucket.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int, now time.Time) *limiter {
+	return &limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+func (l *limiter) allow(now time.Time) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
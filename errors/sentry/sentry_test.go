@@ -0,0 +1,248 @@
+package sentry_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gotech-labs/core/errors"
+	. "github.com/gotech-labs/core/errors/sentry"
+	"github.com/gotech-labs/core/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentryServer is a stand-in for the Sentry envelope endpoint.
+type sentryServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	status  int
+	headers []http.Header
+	events  []Event
+}
+
+func newSentryServer(t *testing.T) *sentryServer {
+	s := &sentryServer{status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.URL.Path != "/api/42/envelope/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if s.status != http.StatusOK {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(s.status)
+			return
+		}
+		s.headers = append(s.headers, r.Header)
+		s.events = append(s.events, parseEnvelope(t, r.Body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *sentryServer) dsn() string {
+	return strings.Replace(s.URL, "://", "://public-key@", 1) + "/42"
+}
+
+func (s *sentryServer) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *sentryServer) received() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+func parseEnvelope(t *testing.T, r io.Reader) Event {
+	scanner := bufio.NewScanner(r)
+	var header, item struct {
+		EventID string `json:"event_id"`
+		DSN     string `json:"dsn"`
+		Type    string `json:"type"`
+		Length  int    `json:"length"`
+	}
+	require.True(t, scanner.Scan())
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &header))
+	require.True(t, scanner.Scan())
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &item))
+	require.True(t, scanner.Scan())
+	payload := scanner.Bytes()
+	assert.Equal(t, "event", item.Type)
+	assert.Equal(t, len(payload), item.Length)
+	var event Event
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, header.EventID, event.EventID)
+	return event
+}
+
+func TestNewEvent(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	err := errors.UnexpectedError.Wrap(
+		errors.InitializationError.Wrap(io.EOF, "database access error", "db", "users"),
+		"nested error message", "userID", 123)
+	event := NewEvent(err, now)
+	assert.Len(t, event.EventID, 32)
+	assert.Equal(t, now.UTC(), event.Timestamp)
+	assert.Equal(t, "go", event.Platform)
+	assert.Equal(t, "error", event.Level)
+	assert.Equal(t, map[string]string{"error.code": "core.unexpected_error"}, event.Tags)
+	assert.Equal(t, map[string]any{"db": "users", "userID": int64(123)}, event.Extra)
+	assert.Equal(t, []string{err.Fingerprint()}, event.Fingerprint)
+
+	values := event.Exception.Values
+	require.Len(t, values, 3)
+	assert.Equal(t, Exception{Type: "*errors.errorString", Value: "EOF"}, values[0])
+	assert.Equal(t, "initialization error", values[1].Type)
+	assert.Equal(t, "database access error", values[1].Value)
+	assert.Equal(t, "core.initialization_error", values[1].Module)
+	assert.Equal(t, "unexpected error", values[2].Type)
+	frames := values[2].Stacktrace.Frames
+	require.NotEmpty(t, frames)
+	top := frames[len(frames)-1]
+	assert.Equal(t, "TestNewEvent", top.Function)
+	assert.Equal(t, "github.com/gotech-labs/core/errors/sentry_test", top.Module)
+	assert.True(t, strings.HasSuffix(top.Filename, "/sentry_test.go"))
+	assert.Positive(t, top.Lineno)
+	assert.True(t, top.InApp)
+
+	event = NewEvent(stderrors.New("mysql open error"), now)
+	assert.Equal(t, []Exception{{Type: "*errors.errorString", Value: "mysql open error"}}, event.Exception.Values)
+	assert.Nil(t, event.Extra)
+}
+
+func TestNew(t *testing.T) {
+	for _, dsn := range []string{
+		"://invalid",
+		"https://sentry.example.com/42",
+		"https://public-key@sentry.example.com",
+	} {
+		_, err := New(dsn)
+		assert.True(t, InvalidDSNError.Is(err), dsn)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	server := newSentryServer(t)
+	exporter, err := New(server.dsn(),
+		WithEnvironment("test"),
+		WithRelease("v1.2.3"),
+		WithBatch(100, time.Hour))
+	require.NoError(t, err)
+
+	exporter.ExportError(context.Background(), errors.ValidationError.New("user name is a required field"))
+	exporter.ExportError(context.Background(), nil)
+	exporter.ExportError(context.Background(), io.EOF)
+	assert.Equal(t, 2, exporter.Pending())
+	assert.Empty(t, server.received())
+
+	require.NoError(t, exporter.Close(context.Background()))
+	events := server.received()
+	require.Len(t, events, 2)
+	assert.Equal(t, "user name is a required field", events[0].Exception.Values[0].Value)
+	assert.Equal(t, "test", events[0].Environment)
+	assert.Equal(t, "v1.2.3", events[0].Release)
+	assert.Equal(t, "EOF", events[1].Exception.Values[0].Value)
+	assert.Equal(t, "application/x-sentry-envelope", server.headers[0].Get("Content-Type"))
+	assert.Contains(t, server.headers[0].Get("X-Sentry-Auth"), "sentry_key=public-key")
+	assert.Zero(t, exporter.Pending())
+}
+
+func TestExporterBatch(t *testing.T) {
+	// a non-positive interval falls back to the default one
+	for _, interval := range []time.Duration{time.Hour, 0, -time.Second} {
+		server := newSentryServer(t)
+		exporter, err := New(server.dsn(), WithBatch(2, interval))
+		require.NoError(t, err)
+
+		exporter.ExportError(context.Background(), io.EOF)
+		exporter.ExportError(context.Background(), io.ErrUnexpectedEOF)
+		assert.Eventually(t, func() bool {
+			return len(server.received()) == 2
+		}, time.Second, 10*time.Millisecond)
+		require.NoError(t, exporter.Close(context.Background()))
+	}
+}
+
+func TestExporterRateLimit(t *testing.T) {
+	server := newSentryServer(t)
+	clock := system.NewFakeClock(time.Now())
+	exporter, err := New(server.dsn(), WithRateLimit(1, 2), WithClock(clock), WithBatch(100, time.Hour))
+	require.NoError(t, err)
+	defer exporter.Close(context.Background())
+
+	for range 3 {
+		exporter.ExportError(context.Background(), io.EOF)
+	}
+	assert.Equal(t, 2, exporter.Pending())
+	assert.Equal(t, 1, exporter.Dropped())
+	clock.Advance(time.Second)
+	exporter.ExportError(context.Background(), io.EOF)
+	assert.Equal(t, 3, exporter.Pending())
+}
+
+func TestExporterOfflineQueue(t *testing.T) {
+	server := newSentryServer(t)
+	clock := system.NewFakeClock(time.Now())
+	exporter, err := New(server.dsn(), WithQueueSize(2), WithClock(clock), WithBatch(100, time.Hour))
+	require.NoError(t, err)
+	defer exporter.Close(context.Background())
+
+	// the events are kept while Sentry is unavailable
+	server.setStatus(http.StatusServiceUnavailable)
+	exporter.ExportError(context.Background(), io.EOF)
+	err = exporter.Flush(context.Background())
+	assert.True(t, TransportError.Is(err))
+	assert.True(t, errors.IsRetryable(err))
+	assert.Equal(t, 1, exporter.Pending())
+
+	// the oldest events are dropped when the queue is full
+	exporter.ExportError(context.Background(), io.ErrUnexpectedEOF)
+	exporter.ExportError(context.Background(), io.ErrShortWrite)
+	assert.Equal(t, 2, exporter.Pending())
+	assert.Equal(t, 1, exporter.Dropped())
+
+	// the rate limits of Sentry are honored
+	server.setStatus(http.StatusTooManyRequests)
+	assert.Error(t, exporter.Flush(context.Background()))
+	server.setStatus(http.StatusOK)
+	clock.Advance(29 * time.Second)
+	require.NoError(t, exporter.Flush(context.Background()))
+	assert.Empty(t, server.received())
+	clock.Advance(time.Second)
+	require.NoError(t, exporter.Flush(context.Background()))
+
+	events := server.received()
+	require.Len(t, events, 2)
+	assert.Equal(t, "unexpected EOF", events[0].Exception.Values[0].Value)
+	assert.Equal(t, "short write", events[1].Exception.Values[0].Value)
+}
+
+func TestExporterClose(t *testing.T) {
+	server := newSentryServer(t)
+	exporter, err := New(server.dsn(), WithBatch(100, time.Hour))
+	require.NoError(t, err)
+
+	exporter.ExportError(context.Background(), errors.UnexpectedError.Wrap(io.EOF, "read failed"))
+	require.NoError(t, exporter.Close(context.Background()))
+	// the errors exported once closed are dropped
+	exporter.ExportError(context.Background(), io.EOF)
+	assert.Zero(t, exporter.Pending())
+	assert.Equal(t, 1, exporter.Dropped())
+
+	events := server.received()
+	require.Len(t, events, 1)
+	assert.Equal(t, "read failed", events[0].Exception.Values[1].Value)
+}
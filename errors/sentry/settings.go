@@ -0,0 +1,124 @@
+package sentry
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gotech-labs/core/internal/pkgpath"
+	"github.com/gotech-labs/core/system"
+)
+
+var defaultSettings = &settings{
+	batchSize:     20,
+	flushInterval: 5 * time.Second,
+	queueSize:     1000,
+	rateLimit:     10,
+	burst:         100,
+	client:        http.DefaultClient,
+	clock:         system.DefaultClock,
+}
+
+type settings struct {
+	environment   string
+	release       string
+	serverName    string
+	inAppPrefixes []string
+	batchSize     int
+	flushInterval time.Duration
+	queueSize     int
+	rateLimit     float64
+	burst         int
+	client        *http.Client
+	clock         system.Clock
+}
+
+type option func(*settings)
+
+func newSettings(options []option) *settings {
+	settings := *defaultSettings
+	for _, option := range options {
+		option(&settings)
+	}
+	return &settings
+}
+
+func (s *settings) inApp(module string) bool {
+	if len(s.inAppPrefixes) == 0 {
+		return !pkgpath.IsStdlib(module)
+	}
+	for _, prefix := range s.inAppPrefixes {
+		if strings.HasPrefix(module, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func WithEnvironment(environment string) option {
+	return func(settings *settings) {
+		settings.environment = environment
+	}
+}
+
+func WithRelease(release string) option {
+	return func(settings *settings) {
+		settings.release = release
+	}
+}
+
+func WithServerName(name string) option {
+	return func(settings *settings) {
+		settings.serverName = name
+	}
+}
+
+// WithInAppPrefixes marks the frames of the packages with the given path
+// prefixes as application frames. All the frames out of the standard
+// library are by default.
+func WithInAppPrefixes(prefixes ...string) option {
+	return func(settings *settings) {
+		settings.inAppPrefixes = prefixes
+	}
+}
+
+// WithBatch sends the queued events every interval, or as soon as size
+// events are queued. A non-positive interval keeps the default one.
+func WithBatch(size int, interval time.Duration) option {
+	return func(settings *settings) {
+		settings.batchSize = max(size, 1)
+		if interval > 0 {
+			settings.flushInterval = interval
+		}
+	}
+}
+
+// WithQueueSize sets the number of events kept while Sentry can't be
+// reached. The oldest events are dropped first.
+func WithQueueSize(size int) option {
+	return func(settings *settings) {
+		settings.queueSize = max(size, 1)
+	}
+}
+
+// WithRateLimit limits the number of exported events per second, allowing
+// bursts of up to burst events. The events over the limit are dropped,
+// and a rate of 0 disables the limit.
+func WithRateLimit(eventsPerSecond float64, burst int) option {
+	return func(settings *settings) {
+		settings.rateLimit = eventsPerSecond
+		settings.burst = max(burst, 1)
+	}
+}
+
+func WithHTTPClient(client *http.Client) option {
+	return func(settings *settings) {
+		settings.client = client
+	}
+}
+
+func WithClock(clock system.Clock) option {
+	return func(settings *settings) {
+		settings.clock = clock
+	}
+}
@@ -2,66 +2,274 @@ package errors
 
 import (
 	"fmt"
+	"go/build"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gotech-labs/core/internal/pkgpath"
+)
+
+// Frame is a symbolized stack frame.
+type Frame struct {
+	// Name is the fully qualified function name,
+	// like "github.com/xxx/package.(*PtrReceiver).MethodName".
+	Name string
+	// Function is the function name without package, like "PtrReceiver.MethodName".
+	Function string
+	File     string
+	Line     int
+}
+
+// FrameFilter reports whether a frame is kept in the stack traces.
+type FrameFilter func(Frame) bool
+
+// DropRuntimeFrames drops the frames of the runtime and testing packages
+// and of vendored packages.
+func DropRuntimeFrames(f Frame) bool {
+	return !strings.HasPrefix(f.Name, "runtime.") &&
+		!strings.HasPrefix(f.Name, "testing.") &&
+		!strings.Contains(f.File, "/vendor/")
+}
+
+// ModuleFrames keeps only the frames of the functions in the given
+// package path prefixes, like "github.com/gotech-labs/".
+func ModuleFrames(prefixes ...string) FrameFilter {
+	return func(f Frame) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(f.Name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// StackMode selects how much of the stack is captured by the errors of a type.
+type StackMode int
+
+const (
+	// FullStack captures up to the configured stack depth.
+	FullStack StackMode = iota
+	// CallerOnly captures the frame creating the error only.
+	CallerOnly
+	// NoStack captures no stack at all, for cheap errors returned on hot paths.
+	NoStack
 )
 
-type frame struct {
-	name     string
-	file     string
-	function string
-	line     int
+func WithStackMode(mode StackMode) option {
+	return func(factory *errorFactory) {
+		factory.stackMode = mode
+	}
+}
+
+type stackSettings struct {
+	depth             int
+	filter            FrameFilter
+	trimPrefixes      []string
+	fingerprintFrames int
+	color             bool
+	sourceLines       int
 }
 
-type stackFrames []frame
+type stackOption func(*stackSettings)
 
-func (st *stackFrames) traceLines() []string {
-	var lines = make([]string, 0, len(*st)-1)
-	for _, s := range *st {
-		lines = append(lines, fmt.Sprintf("    at %s:%d (%s)", s.file, s.line, s.function))
+var currentStackSettings atomic.Pointer[stackSettings]
+
+func init() {
+	currentStackSettings.Store(&stackSettings{
+		depth:             32,
+		filter:            DropRuntimeFrames,
+		fingerprintFrames: 5,
+	})
+}
+
+// SetStackOptions changes how the stacks of the errors are captured and rendered.
+func SetStackOptions(options ...stackOption) {
+	settings := *currentStackSettings.Load()
+	for _, option := range options {
+		option(&settings)
+	}
+	currentStackSettings.Store(&settings)
+}
+
+// WithStackDepth sets the maximum number of frames captured per error.
+func WithStackDepth(depth int) stackOption {
+	return func(settings *stackSettings) {
+		settings.depth = max(depth, 1)
+	}
+}
+
+// WithFrameFilter sets the filter of the rendered frames, nil keeps all of them.
+func WithFrameFilter(filter FrameFilter) stackOption {
+	return func(settings *stackSettings) {
+		settings.filter = filter
+	}
+}
+
+// WithFingerprintFrames sets the number of top frames out of the standard
+// library identifying an error in its fingerprint, whatever the frame filter.
+func WithFingerprintFrames(n int) stackOption {
+	return func(settings *stackSettings) {
+		settings.fingerprintFrames = max(n, 0)
+	}
+}
+
+// WithTrimPrefixes trims the given directories, like the module root,
+// from the file paths of the rendered frames.
+func WithTrimPrefixes(prefixes ...string) stackOption {
+	return func(settings *stackSettings) {
+		for _, prefix := range prefixes {
+			if prefix != "" {
+				settings.trimPrefixes = append(settings.trimPrefixes, filepath.ToSlash(prefix)+"/")
+			}
+		}
+	}
+}
+
+// WithTrimGOPATH trims the GOROOT, the module cache and the GOPATH
+// directories from the file paths of the rendered frames.
+func WithTrimGOPATH() stackOption {
+	prefixes := []string{filepath.Join(runtime.GOROOT(), "src")}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		prefixes = append(prefixes, filepath.Join(gopath, "pkg", "mod"), filepath.Join(gopath, "src"))
+	}
+	return WithTrimPrefixes(prefixes...)
+}
+
+type stackFrames []Frame
+
+func (st stackFrames) traceLines() []string {
+	var lines = make([]string, 0, len(st))
+	for _, s := range st {
+		lines = append(lines, fmt.Sprintf("    at %s:%d (%s)", s.File, s.Line, s.Function))
 	}
 	return lines
 }
 
-type stack []uintptr
+// stack holds the captured program counters, which are symbolized on
+// the first rendering only.
+type stack struct {
+	pcs    []uintptr
+	once   sync.Once
+	frames stackFrames
+}
 
 func (s *stack) StackFrames() stackFrames {
-	frames := make([]frame, len(*s))
-	for i, pc := range *s {
-		fn := runtime.FuncForPC(pc)
-
-		// fn.Name() is like one of these:
-		// - "github.com/xxx/package.FuncName"
-		// - "github.com/xxx/package.Receiver.MethodName"
-		// - "github.com/xxx/package.(*PtrReceiver).MethodName"
-		name := fn.Name()
-		file, line := fn.FileLine(pc - 1)
-		withoutPath := name
-		if pos := strings.LastIndex(name, "/"); pos > 0 {
-			withoutPath = name[pos+1:]
+	if s == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		s.frames = symbolize(s.pcs, currentStackSettings.Load())
+	})
+	return s.frames
+}
+
+// moduleFrameNames returns the names of the first n frames out of the
+// standard library, regardless of the filter of the rendered frames.
+func (s *stack) moduleFrameNames(n int) []string {
+	if s == nil || n == 0 {
+		return nil
+	}
+	names := make([]string, 0, n)
+	if s.pcs == nil {
+		// decoded stacks have their rendered frames only
+		for _, frame := range s.frames {
+			if len(names) == n {
+				break
+			}
+			if !pkgpath.IsStdlib(pkgpath.Of(frame.Name)) {
+				names = append(names, frame.Name)
+			}
 		}
-		withoutPackage := withoutPath
-		if pos := strings.Index(withoutPath, "."); pos > 0 {
-			withoutPackage = withoutPath[pos+1:]
+		return names
+	}
+	callersFrames := runtime.CallersFrames(s.pcs)
+	for len(names) < n {
+		f, more := callersFrames.Next()
+		if !pkgpath.IsStdlib(pkgpath.Of(f.Function)) {
+			names = append(names, f.Function)
 		}
-		function := withoutPackage
-		for _, target := range []string{"(", "*", ")"} {
-			function = strings.Replace(function, target, "", 1)
+		if !more {
+			break
 		}
-		frames[i] = frame{
-			name:     name,
-			file:     file,
-			function: function,
-			line:     line,
+	}
+	return names
+}
+
+// symbolizedStack returns a stack of already symbolized frames.
+func symbolizedStack(frames stackFrames) *stack {
+	s := &stack{frames: frames}
+	s.once.Do(func() {})
+	return s
+}
+
+func symbolize(pcs []uintptr, settings *stackSettings) stackFrames {
+	if len(pcs) == 0 {
+		return nil
+	}
+	frames := make(stackFrames, 0, len(pcs))
+	callersFrames := runtime.CallersFrames(pcs)
+	for {
+		f, more := callersFrames.Next()
+		frame := Frame{
+			Name:     f.Function,
+			Function: shortFunctionName(f.Function),
+			File:     trimPath(f.File, settings.trimPrefixes),
+			Line:     f.Line,
+		}
+		if settings.filter == nil || settings.filter(frame) {
+			frames = append(frames, frame)
+		}
+		if !more {
+			break
 		}
 	}
 	return frames
 }
 
-func callers(skip int) *stack {
-	const depth = 32
-	var pcs [depth]uintptr
-	n := runtime.Callers(skip, pcs[:])
-	var st stack = pcs[0 : n-2]
-	return &st
+func shortFunctionName(name string) string {
+	// name is like one of these:
+	// - "github.com/xxx/package.FuncName"
+	// - "github.com/xxx/package.Receiver.MethodName"
+	// - "github.com/xxx/package.(*PtrReceiver).MethodName"
+	withoutPath := name
+	if pos := strings.LastIndex(name, "/"); pos > 0 {
+		withoutPath = name[pos+1:]
+	}
+	withoutPackage := withoutPath
+	if pos := strings.Index(withoutPath, "."); pos > 0 {
+		withoutPackage = withoutPath[pos+1:]
+	}
+	function := withoutPackage
+	for _, target := range []string{"(", "*", ")"} {
+		function = strings.Replace(function, target, "", 1)
+	}
+	return function
+}
+
+func trimPath(file string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(file, prefix) {
+			return file[len(prefix):]
+		}
+	}
+	return file
+}
+
+func callers(skip int, mode StackMode) *stack {
+	var depth int
+	switch mode {
+	case NoStack:
+		return nil
+	case CallerOnly:
+		depth = 1
+	default:
+		depth = currentStackSettings.Load().depth
+	}
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip, pcs)
+	return &stack{pcs: pcs[:n]}
 }
@@ -0,0 +1,167 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// GRPCCode is a canonical status code compatible with google.golang.org/grpc/codes.
+type GRPCCode uint32
+
+const (
+	OK                 GRPCCode = 0
+	Canceled           GRPCCode = 1
+	Unknown            GRPCCode = 2
+	InvalidArgument    GRPCCode = 3
+	DeadlineExceeded   GRPCCode = 4
+	NotFound           GRPCCode = 5
+	AlreadyExists      GRPCCode = 6
+	PermissionDenied   GRPCCode = 7
+	ResourceExhausted  GRPCCode = 8
+	FailedPrecondition GRPCCode = 9
+	Aborted            GRPCCode = 10
+	OutOfRange         GRPCCode = 11
+	Unimplemented      GRPCCode = 12
+	Internal           GRPCCode = 13
+	Unavailable        GRPCCode = 14
+	DataLoss           GRPCCode = 15
+	Unauthenticated    GRPCCode = 16
+)
+
+var grpcCodeNames = [...]string{
+	"OK", "Canceled", "Unknown", "InvalidArgument", "DeadlineExceeded", "NotFound",
+	"AlreadyExists", "PermissionDenied", "ResourceExhausted", "FailedPrecondition",
+	"Aborted", "OutOfRange", "Unimplemented", "Internal", "Unavailable", "DataLoss",
+	"Unauthenticated",
+}
+
+func (c GRPCCode) String() string {
+	if int(c) < len(grpcCodeNames) {
+		return grpcCodeNames[c]
+	}
+	return "Code(" + strconv.Itoa(int(c)) + ")"
+}
+
+type Status struct {
+	HTTPStatus    int
+	GRPCCode      GRPCCode
+	Retryable     bool
+	PublicMessage string
+}
+
+var (
+	unknownStatus = Status{
+		HTTPStatus: http.StatusInternalServerError,
+		GRPCCode:   Unknown,
+	}
+	canceledStatus = Status{
+		// non-standard status used by nginx for requests closed by the client
+		HTTPStatus: 499,
+		GRPCCode:   Canceled,
+	}
+	deadlineExceededStatus = Status{
+		HTTPStatus: http.StatusGatewayTimeout,
+		GRPCCode:   DeadlineExceeded,
+	}
+)
+
+func WithHTTPStatus(status int) option {
+	return func(factory *errorFactory) {
+		factory.status.HTTPStatus = status
+	}
+}
+
+func WithGRPCCode(code GRPCCode) option {
+	return func(factory *errorFactory) {
+		factory.status.GRPCCode = code
+	}
+}
+
+func WithRetryable(retryable bool) option {
+	return func(factory *errorFactory) {
+		factory.status.Retryable = retryable
+		factory.retryableSet = true
+	}
+}
+
+func WithPublicMessage(message string) option {
+	return func(factory *errorFactory) {
+		factory.status.PublicMessage = message
+	}
+}
+
+// StatusOf resolves the status of err. The chain is walked from the
+// outermost error and the first error type defined with a status wins.
+func StatusOf(err error) Status {
+	if err == nil {
+		return Status{HTTPStatus: http.StatusOK, GRPCCode: OK}
+	}
+	for _, cause := range Chain(err) {
+		switch cause {
+		case context.Canceled:
+			return canceledStatus
+		case context.DeadlineExceeded:
+			return deadlineExceededStatus
+		}
+		if multi, ok := cause.(interface{ Unwrap() []error }); ok {
+			return multiStatus(multi.Unwrap())
+		}
+		e, ok := cause.(*coreError)
+		if !ok || e.factory == nil {
+			continue
+		}
+		if status, ok := e.factory.definedStatus(); ok {
+			if status.HTTPStatus == 0 {
+				status.HTTPStatus = unknownStatus.HTTPStatus
+			}
+			if status.GRPCCode == OK {
+				status.GRPCCode = unknownStatus.GRPCCode
+			}
+			return status
+		}
+	}
+	return unknownStatus
+}
+
+// multiStatus returns the most severe status of errs.
+func multiStatus(errs []error) Status {
+	status := unknownStatus
+	for i, err := range errs {
+		if s := StatusOf(err); i == 0 || s.HTTPStatus > status.HTTPStatus {
+			status = s
+		}
+	}
+	return status
+}
+
+// definedStatus resolves each field of the status from the nearest type
+// in the hierarchy which set it. It reports whether any type did.
+func (ef *errorFactory) definedStatus() (Status, bool) {
+	var status Status
+	for f := ef; f != nil; f = f.parent {
+		if status.HTTPStatus == 0 {
+			status.HTTPStatus = f.status.HTTPStatus
+		}
+		if status.GRPCCode == OK {
+			status.GRPCCode = f.status.GRPCCode
+		}
+		if status.PublicMessage == "" {
+			status.PublicMessage = f.status.PublicMessage
+		}
+	}
+	retryable, retryableSet := ef.definedRetryable()
+	status.Retryable = retryable
+	return status, retryableSet || status != (Status{})
+}
+
+// definedRetryable returns the retryability of the nearest type in the
+// hierarchy which was defined with WithRetryable.
+func (ef *errorFactory) definedRetryable() (bool, bool) {
+	for f := ef; f != nil; f = f.parent {
+		if f.retryableSet {
+			return f.status.Retryable, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,22 @@
+// Package attrmap converts slog attributes to plain maps for encoding.
+package attrmap
+
+import "log/slog"
+
+// FromAttrs returns the values of attrs keyed by their keys, with the
+// groups as nested maps. It returns nil when there is no attribute.
+func FromAttrs(attrs []slog.Attr) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	values := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		value := attr.Value.Resolve()
+		if value.Kind() == slog.KindGroup {
+			values[attr.Key] = FromAttrs(value.Group())
+			continue
+		}
+		values[attr.Key] = value.Any()
+	}
+	return values
+}
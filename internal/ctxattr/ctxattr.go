@@ -0,0 +1,83 @@
+// Package ctxattr holds the registry of the extractors of the attributes
+// carried by a context, shared by the log and errors packages.
+package ctxattr
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Extractor appends the attributes carried by ctx to attrs. It must return
+// attrs unchanged when ctx carries none, so that nothing is allocated.
+type Extractor func(ctx context.Context, attrs []slog.Attr) []slog.Attr
+
+// Value returns an extractor of the value of type T stored in the context
+// under key, as the attribute name.
+func Value[T any](name string, key any) Extractor {
+	return func(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+		if value, ok := ctx.Value(key).(T); ok {
+			return append(attrs, slog.Any(name, value))
+		}
+		return attrs
+	}
+}
+
+type registeredExtractor struct {
+	extract Extractor
+}
+
+var (
+	extractorsMu sync.Mutex
+	// extractors holds the registered extractors in registration order.
+	extractors atomic.Pointer[[]*registeredExtractor]
+)
+
+// Register registers extractors after the ones registered before. It
+// returns a function unregistering them.
+func Register(extractors ...Extractor) (unregister func()) {
+	registered := make([]*registeredExtractor, len(extractors))
+	for i, extract := range extractors {
+		registered[i] = &registeredExtractor{extract: extract}
+	}
+	update(func(current []*registeredExtractor) []*registeredExtractor {
+		return append(current, registered...)
+	})
+	return func() {
+		update(func(current []*registeredExtractor) []*registeredExtractor {
+			return slices.DeleteFunc(current, func(e *registeredExtractor) bool {
+				return slices.Contains(registered, e)
+			})
+		})
+	}
+}
+
+func update(fn func([]*registeredExtractor) []*registeredExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	var current []*registeredExtractor
+	if registered := extractors.Load(); registered != nil {
+		current = slices.Clone(*registered)
+	}
+	updated := fn(current)
+	extractors.Store(&updated)
+}
+
+// Attrs returns the attributes extracted from ctx by the registered
+// extractors.
+func Attrs(ctx context.Context) []slog.Attr {
+	return Append(ctx, nil)
+}
+
+// Append appends the attributes extracted from ctx by the registered
+// extractors to attrs.
+func Append(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+	if registered := extractors.Load(); registered != nil {
+		for _, e := range *registered {
+			attrs = e.extract(ctx, attrs)
+		}
+	}
+	return attrs
+}
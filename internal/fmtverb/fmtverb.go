@@ -0,0 +1,98 @@
+// Package fmtverb inspects fmt style format strings.
+package fmtverb
+
+// Count returns the number of operands consumed by the format string,
+// including the operands of '*' widths and precisions and honoring
+// explicit argument indexes such as "%[2]d".
+func Count(format string) int {
+	var (
+		argNum    = 0
+		maxArgNum = 0
+	)
+	consume := func() {
+		argNum++
+		maxArgNum = max(maxArgNum, argNum)
+	}
+	end := len(format)
+	for i := 0; i < end; i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		// flags
+		for i < end && isFlag(format[i]) {
+			i++
+		}
+		// width
+		i, argNum = argIndex(format, i, argNum)
+		if i < end && format[i] == '*' {
+			consume()
+			i++
+		}
+		i = skipDigits(format, i)
+		// precision
+		if i < end && format[i] == '.' {
+			i++
+			i, argNum = argIndex(format, i, argNum)
+			if i < end && format[i] == '*' {
+				consume()
+				i++
+			}
+			i = skipDigits(format, i)
+		}
+		i, argNum = argIndex(format, i, argNum)
+		if i >= end {
+			break
+		}
+		if format[i] == '%' {
+			continue
+		}
+		consume()
+	}
+	return maxArgNum
+}
+
+func isFlag(c byte) bool {
+	switch c {
+	case '+', '-', '#', ' ', '0':
+		return true
+	}
+	return false
+}
+
+func skipDigits(format string, i int) int {
+	for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+func argIndex(format string, i, argNum int) (int, int) {
+	if i >= len(format) || format[i] != '[' {
+		return i, argNum
+	}
+	for j := i + 1; j < len(format); j++ {
+		if format[j] == ']' {
+			n, ok := parseInt(format[i+1 : j])
+			if !ok || n < 1 {
+				return j + 1, argNum
+			}
+			return j + 1, n - 1
+		}
+	}
+	return i, argNum
+}
+
+func parseInt(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+	return n, true
+}
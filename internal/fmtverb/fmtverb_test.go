@@ -0,0 +1,29 @@
+package fmtverb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCount(t *testing.T) {
+	for _, in := range []struct {
+		format   string
+		expected int
+	}{
+		{format: "", expected: 0},
+		{format: "user name is a required field", expected: 0},
+		{format: "100%% completed", expected: 0},
+		{format: "user %d not found", expected: 1},
+		{format: "user %s (%+v) not found: %%", expected: 2},
+		{format: "%-10s|%08.3f|%x", expected: 3},
+		{format: "%*d", expected: 2},
+		{format: "%.*f", expected: 2},
+		{format: "%[2]s %[1]s", expected: 2},
+		{format: "%[3]*.[2]*[1]f", expected: 3},
+		{format: "%d %[1]d", expected: 1},
+		{format: "trailing %", expected: 0},
+	} {
+		assert.Equal(t, in.expected, Count(in.format), in.format)
+	}
+}
@@ -0,0 +1,22 @@
+// Package pkgpath inspects the import paths of the functions found in stacks.
+package pkgpath
+
+import "strings"
+
+// Of returns the import path of a fully qualified function name like
+// "github.com/xxx/package.init" or "github.com/xxx/package.(*Receiver).Method.func1".
+func Of(name string) string {
+	slash := strings.LastIndex(name, "/") + 1
+	if dot := strings.Index(name[slash:], "."); dot >= 0 {
+		return name[:slash+dot]
+	}
+	return name
+}
+
+// IsStdlib reports whether the import path belongs to the standard library.
+func IsStdlib(path string) bool {
+	// the first element of the import paths out of the standard library
+	// is a domain name, like "github.com/xxx/package"
+	first, _, _ := strings.Cut(path, "/")
+	return first != "main" && !strings.Contains(first, ".")
+}
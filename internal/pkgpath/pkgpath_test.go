@@ -0,0 +1,22 @@
+package pkgpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, "github.com/xxx/package", Of("github.com/xxx/package.init"))
+	assert.Equal(t, "github.com/xxx/package", Of("github.com/xxx/package.(*Receiver).Method.func1"))
+	assert.Equal(t, "net/http", Of("net/http.HandlerFunc.ServeHTTP"))
+	assert.Equal(t, "main", Of("main.main"))
+}
+
+func TestIsStdlib(t *testing.T) {
+	assert.True(t, IsStdlib("runtime"))
+	assert.True(t, IsStdlib("net/http"))
+	assert.False(t, IsStdlib("main"))
+	assert.False(t, IsStdlib("example.com"))
+	assert.False(t, IsStdlib("github.com/xxx/package"))
+}
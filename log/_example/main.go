@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"log/slog"
 	"os"
 
 	"github.com/gotech-labs/core/log"
@@ -27,16 +28,8 @@ func main() {
 	l := log.New(os.Stdout,
 		log.WithFormat(formats.JSON),
 		log.WithLevel(levels.Info),
-		log.WithAttrs(func() map[string]any {
-			return map[string]any{
-				"env": "DEV",
-			}
-		}),
-		log.WithContextAttrs(func(ctx context.Context) map[string]any {
-			return map[string]any{
-				"project": ctx.Value(projectKey{}),
-			}
-		}))
+		log.WithAttrs(slog.String("env", "DEV")),
+		log.WithContextExtractors(log.ContextValue[string]("project", projectKey{})))
 	l.Debug("debug message")                 // no output log message
 	l.DebugWithContext(ctx, "debug message") // no output log message
 	l.Info("info message", "id", 123)
@@ -45,6 +38,20 @@ func main() {
 	l.WarnWithContext(ctx, "warn message", "user", &User{ID: 123, Name: "taro"})
 	l.Error("error message", "cause", "unknown error")
 	l.ErrorWithContext(ctx, "error message", "cause", "unknown error")
+
+	// using child logger
+	child := l.Named("http").With("request_id", "req-123")
+	child.Info("info message", "id", 123)
+
+	// using logger stored in context
+	log.InfoWithContext(log.NewContext(ctx, child), "info message", "id", 123)
+
+	// using console logger for local development
+	c := log.New(os.Stderr,
+		log.WithFormat(formats.Console),
+		log.WithColor(formats.ColorAuto))
+	c.Info("info message", "id", 123)
+	c.Warn("warn message", "user", &User{ID: 123, Name: "taro"})
 }
 
 type User struct {
@@ -0,0 +1,22 @@
+package log
+
+import "context"
+
+type loggerKeyType struct{}
+
+// NewContext returns a copy of ctx carrying logger, which is used by the
+// package-level *WithContext functions.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKeyType{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the global logger.
+func FromContext(ctx context.Context) Logger {
+	if ctx == nil {
+		return globalLogger
+	}
+	if logger, ok := ctx.Value(loggerKeyType{}).(Logger); ok {
+		return logger
+	}
+	return globalLogger
+}
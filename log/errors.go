@@ -0,0 +1,67 @@
+package log
+
+import (
+	"log/slog"
+)
+
+const stackTraceKey = "stack_trace"
+
+// withoutStackTraces replaces the errors in args that render themselves
+// as a slog group with values omitting their stack traces.
+func withoutStackTraces(args []any) []any {
+	var replaced []any
+	replace := func(i int, value any) {
+		if replaced == nil {
+			replaced = make([]any, len(args))
+			copy(replaced, args)
+		}
+		replaced[i] = value
+	}
+	for i := 0; i < len(args); i++ {
+		switch x := args[i].(type) {
+		case string:
+			if i+1 == len(args) {
+				continue
+			}
+			i++
+			if valuer, ok := errorValuer(args[i]); ok {
+				replace(i, stackTraceStripper{valuer})
+			}
+		case slog.Attr:
+			if valuer, ok := errorValuer(x.Value.Any()); ok {
+				replace(i, slog.Any(x.Key, stackTraceStripper{valuer}))
+			}
+		}
+	}
+	if replaced == nil {
+		return args
+	}
+	return replaced
+}
+
+func errorValuer(value any) (slog.LogValuer, bool) {
+	if _, ok := value.(error); !ok {
+		return nil, false
+	}
+	valuer, ok := value.(slog.LogValuer)
+	return valuer, ok
+}
+
+type stackTraceStripper struct {
+	valuer slog.LogValuer
+}
+
+func (s stackTraceStripper) LogValue() slog.Value {
+	value := s.valuer.LogValue().Resolve()
+	if value.Kind() != slog.KindGroup {
+		return value
+	}
+	attrs := value.Group()
+	stripped := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Key != stackTraceKey {
+			stripped = append(stripped, attr)
+		}
+	}
+	return slog.GroupValue(stripped...)
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ErrorExporter receives the errors logged at the ERROR level, to ship
+// them to an error tracker.
+type ErrorExporter interface {
+	ExportError(ctx context.Context, err error)
+}
+
+// NewErrorExportHandler returns a handler passing the records to next and
+// the errors of the ERROR records to exporter.
+func NewErrorExportHandler(next slog.Handler, exporter ErrorExporter) slog.Handler {
+	return &errorExportHandler{Handler: next, exporter: exporter}
+}
+
+type errorExportHandler struct {
+	slog.Handler
+	exporter ErrorExporter
+}
+
+func (h *errorExportHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError {
+		record.Attrs(func(attr slog.Attr) bool {
+			h.export(ctx, attr)
+			return true
+		})
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// export exports the error of attr, looking into the groups the logger
+// nests the attributes in.
+func (h *errorExportHandler) export(ctx context.Context, attr slog.Attr) {
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, attr := range attr.Value.Group() {
+			h.export(ctx, attr)
+		}
+		return
+	}
+	if err, ok := exportedError(attr.Value); ok {
+		h.exporter.ExportError(ctx, err)
+	}
+}
+
+func (h *errorExportHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &errorExportHandler{Handler: h.Handler.WithAttrs(attrs), exporter: h.exporter}
+}
+
+func (h *errorExportHandler) WithGroup(name string) slog.Handler {
+	return &errorExportHandler{Handler: h.Handler.WithGroup(name), exporter: h.exporter}
+}
+
+func exportedError(value slog.Value) (error, bool) {
+	switch x := value.Any().(type) {
+	case stackTraceStripper:
+		err, ok := x.valuer.(error)
+		return err, ok
+	case error:
+		return x, true
+	}
+	return nil, false
+}
@@ -0,0 +1,32 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gotech-labs/core/internal/ctxattr"
+)
+
+// ContextExtractor appends the attributes carried by ctx to attrs. It
+// must return attrs unchanged when ctx carries none, so that nothing is
+// allocated.
+type ContextExtractor = ctxattr.Extractor
+
+// ContextValue returns an extractor of the value of type T stored in the
+// context under key, as the attribute name.
+func ContextValue[T any](name string, key any) ContextExtractor {
+	return ctxattr.Value[T](name, key)
+}
+
+// RegisterContextExtractor registers extractors applied by all the loggers
+// and by the errors created with a context, after the ones registered
+// before. It returns a function unregistering them.
+func RegisterContextExtractor(extractors ...ContextExtractor) (unregister func()) {
+	return ctxattr.Register(extractors...)
+}
+
+// ContextAttrs returns the attributes extracted from ctx by the registered
+// extractors.
+func ContextAttrs(ctx context.Context) []slog.Attr {
+	return ctxattr.Attrs(ctx)
+}
@@ -0,0 +1,381 @@
+package formats
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Console is a format for humans reading the logs in a terminal, see
+// ConsoleFormat.
+var Console ConsoleFormat = &consoleFormat{}
+
+// ConsoleFormat is a Format whose rendering can be tuned. When pretty, the
+// messages are aligned, the groups are indented on their own lines and
+// the error stack traces are rendered one frame per line.
+type ConsoleFormat interface {
+	Format
+	NewConsoleHandler(out io.Writer, options *slog.HandlerOptions, pretty bool, color Color) slog.Handler
+}
+
+// Color tells when the console format uses the ANSI colors.
+type Color int
+
+const (
+	// ColorAuto enables the colors when the output is a terminal and the
+	// NO_COLOR environment variable is not set.
+	ColorAuto Color = iota
+	ColorAlways
+	ColorNever
+)
+
+const (
+	consoleTimeFormat = "15:04:05.000"
+	// messageWidth is the width of the message column in pretty mode.
+	messageWidth = 40
+	indent       = "    "
+
+	ansiReset  = "\x1b[0m"
+	ansiFaint  = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+)
+
+type consoleFormat struct{}
+
+func (f *consoleFormat) NewHandler(out io.Writer, options *slog.HandlerOptions) slog.Handler {
+	return f.NewConsoleHandler(out, options, true, ColorAuto)
+}
+
+func (f *consoleFormat) NewConsoleHandler(out io.Writer, options *slog.HandlerOptions, pretty bool, color Color) slog.Handler {
+	if options == nil {
+		options = &slog.HandlerOptions{}
+	}
+	return &consoleHandler{
+		out:     out,
+		mu:      &sync.Mutex{},
+		options: *options,
+		pretty:  pretty,
+		color:   color == ColorAlways || (color == ColorAuto && isTerminal(out)),
+		scopes:  []scope{{}},
+	}
+}
+
+// isTerminal reports whether out is a terminal, without the help of the
+// system calls of the terminal packages.
+func isTerminal(out io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// scope holds the attributes added to a group by WithAttrs.
+type scope struct {
+	group string
+	attrs []slog.Attr
+}
+
+type consoleHandler struct {
+	out     io.Writer
+	mu      *sync.Mutex
+	options slog.HandlerOptions
+	pretty  bool
+	color   bool
+	// scopes starts with the root scope, followed by the open groups.
+	scopes []scope
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.options.Level != nil {
+		minLevel = h.options.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := h.clone()
+	last := &clone.scopes[len(clone.scopes)-1]
+	last.attrs = append(last.attrs[:len(last.attrs):len(last.attrs)], attrs...)
+	return clone
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.scopes = append(clone.scopes, scope{group: name})
+	return clone
+}
+
+func (h *consoleHandler) clone() *consoleHandler {
+	clone := *h
+	clone.scopes = append([]scope(nil), h.scopes...)
+	return &clone
+}
+
+func (h *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	buf := &bytes.Buffer{}
+	h.writeHeader(buf, record)
+
+	// nest the attributes of the record into the open groups
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	for i := len(h.scopes) - 1; i >= 0; i-- {
+		attrs = append(h.scopes[i].attrs[:len(h.scopes[i].attrs):len(h.scopes[i].attrs)], attrs...)
+		if i > 0 {
+			attrs = []slog.Attr{{Key: h.scopes[i].group, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	attrs = h.replaceAttrs(nil, attrs)
+
+	if h.pretty {
+		h.writePrettyAttrs(buf, attrs)
+	} else {
+		h.writeInlineAttrs(buf, "", attrs)
+		buf.WriteByte('\n')
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out.Write(buf.Bytes())
+	return err
+}
+
+func (h *consoleHandler) writeHeader(buf *bytes.Buffer, record slog.Record) {
+	timestamp := slog.Time(slog.TimeKey, record.Time)
+	if h.options.ReplaceAttr != nil && !record.Time.IsZero() {
+		timestamp = h.options.ReplaceAttr(nil, timestamp)
+	}
+	if value := timestamp.Value.Resolve(); value.Kind() == slog.KindTime {
+		h.colored(buf, ansiFaint, value.Time().Format(consoleTimeFormat))
+		buf.WriteByte(' ')
+	}
+	level := fmt.Sprintf("%-5s", record.Level.String())
+	h.colored(buf, levelColor(record.Level), level)
+	buf.WriteByte(' ')
+	buf.WriteString(record.Message)
+}
+
+func (h *consoleHandler) writePrettyAttrs(buf *bytes.Buffer, attrs []slog.Attr) {
+	var blocks []slog.Attr
+	inline := false
+	for _, attr := range attrs {
+		if isBlock(attr.Value) {
+			blocks = append(blocks, attr)
+			continue
+		}
+		if !inline {
+			// align the inline attributes of the successive lines
+			if n := len([]rune(lastLine(buf))); n < messageWidth {
+				buf.WriteString(strings.Repeat(" ", messageWidth-n))
+			}
+			inline = true
+		}
+		buf.WriteByte(' ')
+		h.writeInlineAttr(buf, "", attr)
+	}
+	buf.WriteByte('\n')
+	for _, attr := range blocks {
+		h.writeBlock(buf, indent, attr)
+	}
+}
+
+// writeBlock renders a group or a list of lines, like a stack trace,
+// below its key.
+func (h *consoleHandler) writeBlock(buf *bytes.Buffer, prefix string, attr slog.Attr) {
+	buf.WriteString(prefix)
+	h.colored(buf, ansiCyan, attr.Key+":")
+	buf.WriteByte('\n')
+	if lines, ok := stringLines(attr.Value); ok {
+		for _, line := range lines {
+			buf.WriteString(prefix + indent + line + "\n")
+		}
+		return
+	}
+	for _, attr := range attr.Value.Group() {
+		if isBlock(attr.Value) {
+			h.writeBlock(buf, prefix+indent, attr)
+			continue
+		}
+		buf.WriteString(prefix + indent)
+		h.colored(buf, ansiCyan, attr.Key+":")
+		buf.WriteByte(' ')
+		if attr.Value.Kind() == slog.KindString {
+			// the values alone on their lines need no quotes
+			buf.WriteString(attr.Value.String())
+		} else {
+			buf.WriteString(formatValue(attr.Value))
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+func (h *consoleHandler) writeInlineAttrs(buf *bytes.Buffer, prefix string, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		if attr.Value.Kind() == slog.KindGroup {
+			h.writeInlineAttrs(buf, prefix+attr.Key+".", attr.Value.Group())
+			continue
+		}
+		buf.WriteByte(' ')
+		h.writeInlineAttr(buf, prefix, attr)
+	}
+}
+
+func (h *consoleHandler) writeInlineAttr(buf *bytes.Buffer, prefix string, attr slog.Attr) {
+	h.colored(buf, ansiCyan, prefix+attr.Key+"=")
+	buf.WriteString(formatValue(attr.Value))
+}
+
+// replaceAttrs resolves the values, applies the ReplaceAttr option and
+// drops the empty attributes and groups.
+func (h *consoleHandler) replaceAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	replaced := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		if attr.Value.Kind() == slog.KindGroup {
+			group := attr.Value.Group()
+			if attr.Key != "" {
+				group = h.replaceAttrs(append(groups, attr.Key), group)
+			} else {
+				group = h.replaceAttrs(groups, group)
+			}
+			if len(group) == 0 {
+				continue
+			}
+			if attr.Key == "" {
+				// inline the groups without key
+				replaced = append(replaced, group...)
+				continue
+			}
+			attr.Value = slog.GroupValue(group...)
+		} else if h.options.ReplaceAttr != nil {
+			attr = h.options.ReplaceAttr(groups, attr)
+			attr.Value = attr.Value.Resolve()
+		}
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+		replaced = append(replaced, attr)
+	}
+	return replaced
+}
+
+func (h *consoleHandler) colored(buf *bytes.Buffer, color string, s string) {
+	if !h.color {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteString(color)
+	buf.WriteString(s)
+	buf.WriteString(ansiReset)
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return ansiRed
+	case level >= slog.LevelWarn:
+		return ansiYellow
+	case level >= slog.LevelInfo:
+		return ansiGreen
+	default:
+		return ansiBlue
+	}
+}
+
+func isBlock(value slog.Value) bool {
+	if value.Kind() == slog.KindGroup {
+		return true
+	}
+	_, ok := stringLines(value)
+	return ok
+}
+
+// stringLines returns the lines of the string slices, like the error
+// stack traces.
+func stringLines(value slog.Value) ([]string, bool) {
+	if value.Kind() != slog.KindAny {
+		return nil, false
+	}
+	v := reflect.ValueOf(value.Any())
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.String {
+		return nil, false
+	}
+	lines := make([]string, v.Len())
+	for i := range lines {
+		lines[i] = v.Index(i).String()
+	}
+	return lines, true
+}
+
+func formatValue(value slog.Value) string {
+	switch value.Kind() {
+	case slog.KindString:
+		return quoteIfNeeded(value.String())
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return quoteIfNeeded(err.Error())
+		}
+		return quoteIfNeeded(fmt.Sprintf("%+v", value.Any()))
+	}
+	return value.String()
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+func lastLine(buf *bytes.Buffer) string {
+	data := buf.Bytes()
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	// the width of the colored text excludes the escape sequences
+	s := string(data)
+	for {
+		start := strings.Index(s, "\x1b[")
+		if start < 0 {
+			return s
+		}
+		end := strings.IndexByte(s[start:], 'm')
+		if end < 0 {
+			return s
+		}
+		s = s[:start] + s[start+end+1:]
+	}
+}
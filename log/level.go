@@ -0,0 +1,96 @@
+package log
+
+import (
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gotech-labs/core/log/levels"
+)
+
+// globalLevel is the level shared by the loggers created without
+// WithLevel or WithLevelVar.
+var globalLevel = func() *slog.LevelVar {
+	v := &slog.LevelVar{}
+	v.Set(slog.Level(levels.Debug))
+	return v
+}()
+
+var (
+	overridesMu sync.Mutex
+	// levelOverrides maps the logger names to their level.
+	levelOverrides atomic.Pointer[map[string]slog.Level]
+)
+
+// SetLevel changes the shared level at runtime.
+func SetLevel(level levels.Level) {
+	globalLevel.Set(slog.Level(level))
+}
+
+// GetLevel returns the shared level.
+func GetLevel() levels.Level {
+	return levels.Level(globalLevel.Level())
+}
+
+// SetLevelOverride sets the level of the loggers named name, or prefixed
+// by name and a "." or a "/", like the "db" override applying to the
+// "db.pool" logger. It takes precedence over the level of the loggers.
+func SetLevelOverride(name string, level levels.Level) {
+	updateOverrides(func(overrides map[string]slog.Level) {
+		overrides[name] = slog.Level(level)
+	})
+}
+
+// RemoveLevelOverride removes the override of name.
+func RemoveLevelOverride(name string) {
+	updateOverrides(func(overrides map[string]slog.Level) {
+		delete(overrides, name)
+	})
+}
+
+// LevelOverrides returns the level overrides by name.
+func LevelOverrides() map[string]levels.Level {
+	overrides := make(map[string]levels.Level)
+	if current := levelOverrides.Load(); current != nil {
+		for name, level := range *current {
+			overrides[name] = levels.Level(level)
+		}
+	}
+	return overrides
+}
+
+func updateOverrides(update func(map[string]slog.Level)) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides := make(map[string]slog.Level)
+	if current := levelOverrides.Load(); current != nil {
+		maps.Copy(overrides, *current)
+	}
+	update(overrides)
+	levelOverrides.Store(&overrides)
+}
+
+// leveler resolves the level of a named logger on every record, so that
+// the level changes apply to the existing loggers.
+type leveler struct {
+	name  string
+	level slog.Leveler
+}
+
+func (l *leveler) Level() slog.Level {
+	if overrides := levelOverrides.Load(); overrides != nil && len(*overrides) > 0 {
+		for name := l.name; ; {
+			if level, ok := (*overrides)[name]; ok {
+				return level
+			}
+			i := strings.LastIndexAny(name, "./")
+			if i < 0 {
+				break
+			}
+			name = name[:i]
+		}
+	}
+	return l.level.Level()
+}
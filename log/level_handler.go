@@ -0,0 +1,61 @@
+package log
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gotech-labs/core/log/levels"
+)
+
+// levelsDocument is the JSON document of the levels handler, like
+// {"level":"INFO","overrides":{"db":"DEBUG"}}.
+type levelsDocument struct {
+	Level     *slog.Level           `json:"level,omitempty"`
+	Overrides map[string]slog.Level `json:"overrides"`
+}
+
+// LevelHandler exposes the shared level and the level overrides. GET
+// returns them and PUT changes them: the level is changed when given, and
+// the overrides are replaced when given, a null level removing an
+// override.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var doc struct {
+				Level     *slog.Level             `json:"level"`
+				Overrides *map[string]*slog.Level `json:"overrides"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if doc.Level != nil {
+				SetLevel(levels.Level(*doc.Level))
+			}
+			if doc.Overrides != nil {
+				updateOverrides(func(overrides map[string]slog.Level) {
+					clear(overrides)
+					for name, level := range *doc.Overrides {
+						if level != nil {
+							overrides[name] = *level
+						}
+					}
+				})
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		level := globalLevel.Level()
+		doc := levelsDocument{Level: &level, Overrides: make(map[string]slog.Level)}
+		for name, level := range LevelOverrides() {
+			doc.Overrides[name] = slog.Level(level)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(doc)
+	})
+}
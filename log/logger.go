@@ -4,8 +4,12 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"math"
 	"os"
+	"slices"
+	"time"
 
+	"github.com/gotech-labs/core/internal/ctxattr"
 	"github.com/gotech-labs/core/log/formats"
 	"github.com/gotech-labs/core/log/levels"
 	"github.com/gotech-labs/core/system"
@@ -18,21 +22,21 @@ var (
 func New(out io.Writer, options ...option) Logger {
 	// init settings
 	settings := &settings{
-		out:                  out,
-		format:               formats.JSON,
-		level:                levels.Debug,
-		withAttrsFunc:        func() map[string]any { return nil },
-		withContextAttrsFunc: func(context.Context) map[string]any { return nil },
-		pretty:               true,
+		out:             out,
+		format:          formats.JSON,
+		level:           globalLevel,
+		stackTraceLevel: levels.Debug,
+		pretty:          true,
 	}
 	// bind options
 	for _, option := range options {
 		option(settings)
 	}
 	// create slog handler
-	handler := settings.format.NewHandler(out, &slog.HandlerOptions{
+	handlerOptions := &slog.HandlerOptions{
 		AddSource: false,
-		Level:     slog.Level(settings.level),
+		// the level is checked by the logger, see logger.log
+		Level: slog.Level(math.MinInt),
 		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
 			switch attr.Key {
 			case slog.TimeKey:
@@ -40,17 +44,26 @@ func New(out io.Writer, options ...option) Logger {
 			}
 			return attr
 		},
-	})
+	}
+	var handler slog.Handler
+	if console, ok := settings.format.(formats.ConsoleFormat); ok {
+		handler = console.NewConsoleHandler(out, handlerOptions, settings.pretty, settings.color)
+	} else {
+		handler = settings.format.NewHandler(out, handlerOptions)
+	}
+	if settings.errorExporter != nil {
+		handler = NewErrorExportHandler(handler, settings.errorExporter)
+	}
 	// setup global attributes
-	keyValues := settings.withAttrsFunc()
-	attrs := make([]any, 0, len(keyValues))
-	for k, v := range keyValues {
-		attrs = append(attrs, k, v)
+	if len(settings.attrs) > 0 {
+		handler = handler.WithAttrs(settings.attrs)
 	}
 	// create logger
 	return &logger{
-		internal: slog.New(handler).With(attrs...),
+		internal: slog.New(handler),
 		settings: settings,
+		name:     settings.name,
+		leveler:  &leveler{name: settings.name, level: settings.level},
 	}
 }
 
@@ -63,11 +76,33 @@ type Logger interface {
 	WarnWithContext(ctx context.Context, msg string, args ...any)
 	Error(msg string, args ...any)
 	ErrorWithContext(ctx context.Context, msg string, args ...any)
+	// With returns a child logger adding args to every record.
+	With(args ...any) Logger
+	// WithGroup returns a child logger nesting the attributes of the
+	// records in the group name.
+	WithGroup(name string) Logger
+	// Named returns a child logger named after its parent and name, like
+	// "http.router", whose level can be overridden by SetLevelOverride.
+	Named(name string) Logger
 }
 
+const loggerKey = "logger"
+
 type logger struct {
 	internal *slog.Logger
 	settings *settings
+	name     string
+	leveler  *leveler
+	// groups are the open groups. They are nested in the records rather
+	// than opened on the handler, so that the name and the context
+	// attributes are kept out of them.
+	groups []group
+}
+
+// group is an open group and the attributes added to it by With.
+type group struct {
+	name  string
+	attrs []slog.Attr
 }
 
 func (l *logger) Debug(msg string, args ...any) {
@@ -102,16 +137,93 @@ func (l *logger) ErrorWithContext(ctx context.Context, msg string, args ...any)
 	l.log(ctx, slog.LevelError, msg, args...)
 }
 
+func (l *logger) With(args ...any) Logger {
+	child := *l
+	if len(l.groups) == 0 {
+		child.internal = l.internal.With(args...)
+		return &child
+	}
+	child.groups = slices.Clone(l.groups)
+	last := &child.groups[len(child.groups)-1]
+	last.attrs = append(slices.Clip(last.attrs), argsToAttrs(args)...)
+	return &child
+}
+
+func (l *logger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	child := *l
+	child.groups = append(slices.Clip(l.groups), group{name: name})
+	return &child
+}
+
+func (l *logger) Named(name string) Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	child := *l
+	child.name = name
+	child.leveler = &leveler{name: name, level: l.settings.level}
+	return &child
+}
+
 func (l *logger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
-	if ctx != context.Background() && l.settings.withAttrsFunc != nil {
-		attrs := l.settings.withContextAttrsFunc(ctx)
+	if level < l.leveler.Level() {
+		return
+	}
+	if level < slog.Level(l.settings.stackTraceLevel) {
+		args = withoutStackTraces(args)
+	}
+	var contextAttrs []slog.Attr
+	if ctx != nil && ctx != context.Background() {
+		contextAttrs = l.contextAttrs(ctx)
+	}
+	record := slog.NewRecord(system.CurrentTime(), level, msg, 0)
+	if l.name != "" {
+		record.AddAttrs(slog.String(loggerKey, l.name))
+	}
+	if len(l.groups) == 0 {
+		record.Add(args...)
+		record.AddAttrs(contextAttrs...)
+	} else {
+		record.AddAttrs(contextAttrs...)
+		record.AddAttrs(l.nestInGroups(argsToAttrs(args))...)
+	}
+	_ = l.internal.Handler().Handle(ctx, record)
+}
+
+// nestInGroups nests attrs in the open groups. The groups left empty are
+// dropped, like slog does for the groups opened on a handler.
+func (l *logger) nestInGroups(attrs []slog.Attr) []slog.Attr {
+	for i := len(l.groups) - 1; i >= 0; i-- {
+		attrs = append(slices.Clip(l.groups[i].attrs), attrs...)
 		if len(attrs) > 0 {
-			for k, v := range attrs {
-				args = append(args, k, v)
-			}
+			attrs = []slog.Attr{{Key: l.groups[i].name, Value: slog.GroupValue(attrs...)}}
 		}
 	}
-	l.internal.Log(ctx, level, msg, args...)
+	return attrs
+}
+
+// argsToAttrs converts args, alternating keys and values or holding
+// slog.Attr, to attributes the way slog.Logger does.
+func argsToAttrs(args []any) []slog.Attr {
+	record := slog.NewRecord(time.Time{}, 0, "", 0)
+	record.Add(args...)
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
+
+func (l *logger) contextAttrs(ctx context.Context) []slog.Attr {
+	attrs := ctxattr.Attrs(ctx)
+	for _, extract := range l.settings.contextExtractors {
+		attrs = extract(ctx, attrs)
+	}
+	return attrs
 }
 
 func SetGlobalLogger(out io.Writer, options ...option) {
@@ -123,7 +235,7 @@ func Debug(msg string, args ...any) {
 }
 
 func DebugWithContext(ctx context.Context, msg string, args ...any) {
-	globalLogger.DebugWithContext(ctx, msg, args...)
+	FromContext(ctx).DebugWithContext(ctx, msg, args...)
 }
 
 func Info(msg string, args ...any) {
@@ -131,7 +243,7 @@ func Info(msg string, args ...any) {
 }
 
 func InfoWithContext(ctx context.Context, msg string, args ...any) {
-	globalLogger.InfoWithContext(ctx, msg, args...)
+	FromContext(ctx).InfoWithContext(ctx, msg, args...)
 }
 
 func Warn(msg string, args ...any) {
@@ -139,7 +251,7 @@ func Warn(msg string, args ...any) {
 }
 
 func WarnWithContext(ctx context.Context, msg string, args ...any) {
-	globalLogger.WarnWithContext(ctx, msg, args...)
+	FromContext(ctx).WarnWithContext(ctx, msg, args...)
 }
 
 func Error(msg string, args ...any) {
@@ -147,5 +259,19 @@ func Error(msg string, args ...any) {
 }
 
 func ErrorWithContext(ctx context.Context, msg string, args ...any) {
-	globalLogger.ErrorWithContext(ctx, msg, args...)
+	FromContext(ctx).ErrorWithContext(ctx, msg, args...)
+}
+
+// With returns a child logger of the global logger adding args to every
+// record.
+func With(args ...any) Logger {
+	return globalLogger.With(args...)
+}
+
+func WithGroup(name string) Logger {
+	return globalLogger.WithGroup(name)
+}
+
+func Named(name string) Logger {
+	return globalLogger.Named(name)
 }
@@ -3,15 +3,23 @@ package log_test
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gotech-labs/core/errors"
 	. "github.com/gotech-labs/core/log"
 	"github.com/gotech-labs/core/log/formats"
 	"github.com/gotech-labs/core/log/levels"
 	"github.com/gotech-labs/core/testing/runner"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGlobalLogger(t *testing.T) {
@@ -82,3 +90,276 @@ func TestLogger(t *testing.T) {
 		}
 	})
 }
+
+func TestErrorValue(t *testing.T) {
+	runner.RunTest(t, "logging error test", func(t *testing.T) {
+		err := errors.InitializationError.Wrap(
+			fmt.Errorf("mysql open error"), "database access error", "userID", 123)
+		{
+			buf := bytes.NewBuffer(nil)
+			l := New(buf, WithFormat(formats.Text), WithStackTrace(levels.Error))
+			l.Warn("request failed", "error", err)
+			expected := `time=` + runner.TestingTimeStr + ` level=WARN msg="request failed"` +
+				` error.type="initialization error" error.code=core.initialization_error error.msg="database access error"` +
+				` error.attrs.userID=123 error.cause="mysql open error"` + "\n"
+			assert.Equal(t, expected, buf.String())
+		}
+		{
+			buf := bytes.NewBuffer(nil)
+			l := New(buf, WithFormat(formats.JSON), WithStackTrace(levels.Error))
+			l.Warn("request failed", slog.Any("error", err))
+			expected := `{"time":"` + runner.TestingTimeStr + `","level":"WARN","msg":"request failed",` +
+				`"error":{"type":"initialization error","code":"core.initialization_error","msg":"database access error",` +
+				`"attrs":{"userID":123},"cause":"mysql open error"}}` + "\n"
+			assert.Equal(t, expected, buf.String())
+		}
+		for _, format := range []formats.Format{formats.Text, formats.JSON} {
+			buf := bytes.NewBuffer(nil)
+			l := New(buf, WithFormat(format), WithStackTrace(levels.Error))
+			l.Error("request failed", "error", err)
+			assert.Contains(t, buf.String(), "stack_trace")
+			assert.Contains(t, buf.String(), "logger_test.go")
+		}
+	})
+}
+
+type errorRecorder []error
+
+func (r *errorRecorder) ExportError(_ context.Context, err error) {
+	*r = append(*r, err)
+}
+
+func TestErrorExporter(t *testing.T) {
+	err := errors.InitializationError.New("database access error")
+	var exported errorRecorder
+	l := New(bytes.NewBuffer(nil), WithErrorExporter(&exported), WithStackTrace(levels.Error+1))
+	l.Warn("request failed", "error", err)
+	l.Error("request failed", "user", "gopher")
+	l.Error("request failed", "error", err)
+	l.ErrorWithContext(context.Background(), "request failed", slog.Any("cause", io.EOF))
+	l.WithGroup("request").Error("request failed", "error", err)
+	assert.Equal(t, errorRecorder{err, io.EOF, err}, exported)
+}
+
+func TestConsoleFormat(t *testing.T) {
+	runner.RunTest(t, "console format test", func(t *testing.T) {
+		err := errors.InitializationError.Wrap(fmt.Errorf("mysql open error"), "database access error", "userID", 123)
+		stackTrace := errors.AsError(err).StackTrace()
+		{
+			buf := bytes.NewBuffer(nil)
+			l := New(buf, WithFormat(formats.Console), WithColor(formats.ColorNever))
+			l.Info("info message", "id", 123, "name", "taro yamada")
+			l.Error("request failed", "error", err, slog.Group("request", "method", "GET"))
+			expected := []string{
+				`03:04:05.123 INFO  info message          id=123 name="taro yamada"`,
+				`03:04:05.123 ERROR request failed`,
+				`    error:`,
+				`        type: initialization error`,
+				`        code: core.initialization_error`,
+				`        msg: database access error`,
+				`        attrs:`,
+				`            userID: 123`,
+				`        cause: mysql open error`,
+				`        stack_trace:`,
+			}
+			for _, line := range stackTrace {
+				expected = append(expected, `            `+line)
+			}
+			expected = append(expected,
+				`    request:`,
+				`        method: GET`,
+			)
+			assert.Equal(t, strings.Join(expected, "\n")+"\n", buf.String())
+		}
+		{
+			buf := bytes.NewBuffer(nil)
+			l := New(buf, WithFormat(formats.Console), WithPretty(false), WithColor(formats.ColorAlways),
+				WithStackTrace(levels.Error))
+			l.Warn("request failed", "error", err)
+			expected := "\x1b[2m03:04:05.123\x1b[0m \x1b[33mWARN \x1b[0m request failed" +
+				` ` + "\x1b[36merror.type=\x1b[0m" + `"initialization error"` +
+				` ` + "\x1b[36merror.code=\x1b[0m" + `core.initialization_error` +
+				` ` + "\x1b[36merror.msg=\x1b[0m" + `"database access error"` +
+				` ` + "\x1b[36merror.attrs.userID=\x1b[0m" + `123` +
+				` ` + "\x1b[36merror.cause=\x1b[0m" + `"mysql open error"` + "\n"
+			assert.Equal(t, expected, buf.String())
+		}
+		{
+			buf := bytes.NewBuffer(nil)
+			l := New(buf, WithFormat(formats.Console))
+			l.Debug("debug message")
+			assert.Equal(t, "03:04:05.123 DEBUG debug message\n", buf.String())
+		}
+	})
+}
+
+func TestLevel(t *testing.T) {
+	defer SetLevel(GetLevel())
+	buf := bytes.NewBuffer(nil)
+	shared := New(buf, WithFormat(formats.Text))
+	fixed := New(buf, WithFormat(formats.Text), WithLevel(levels.Warn))
+	named := New(buf, WithFormat(formats.Text), WithName("db.pool"))
+
+	SetLevel(levels.Info)
+	assert.Equal(t, levels.Info, GetLevel())
+	shared.Debug("shared debug")
+	shared.Info("shared info")
+	fixed.Info("fixed info")
+
+	SetLevelOverride("db", levels.Error)
+	defer RemoveLevelOverride("db")
+	assert.Equal(t, map[string]levels.Level{"db": levels.Error}, LevelOverrides())
+	named.Warn("named warn")
+	named.Error("named error")
+	SetLevelOverride("db.pool", levels.Debug)
+	defer RemoveLevelOverride("db.pool")
+	named.Debug("named debug")
+
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		_, msg, _ := strings.Cut(line, "msg=")
+		msgs = append(msgs, msg)
+	}
+	assert.Equal(t, []string{`"shared info"`, `"named error" logger=db.pool`, `"named debug" logger=db.pool`}, msgs)
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer SetLevel(GetLevel())
+	SetLevel(levels.Info)
+	handler := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/levels", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"INFO","overrides":{}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/levels",
+		strings.NewReader(`{"level":"DEBUG","overrides":{"db":"WARN","http":null}}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"DEBUG","overrides":{"db":"WARN"}}`, rec.Body.String())
+	assert.Equal(t, levels.Debug, GetLevel())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/levels", strings.NewReader(`{"overrides":{}}`)))
+	assert.JSONEq(t, `{"level":"DEBUG","overrides":{}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/levels", strings.NewReader(`{"level":"LOUD"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/log/levels", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestChildLogger(t *testing.T) {
+	runner.RunTest(t, "child logger test", func(t *testing.T) {
+		type projectKey struct{}
+		ctx := context.WithValue(context.Background(), projectKey{}, "test")
+		buf := bytes.NewBuffer(nil)
+		l := New(buf, WithFormat(formats.JSON), WithLevel(levels.Info),
+			WithContextExtractors(ContextValue[string]("project", projectKey{})))
+		child := l.Named("http").With("request_id", "req-123").Named("router")
+		child.InfoWithContext(ctx, "info message", "id", 123)
+		child.Debug("debug message")
+		child.WithGroup("user").With("name", "taro").WarnWithContext(ctx, "warn message", "id", 123)
+		// the name is kept at the top level whenever it is given
+		child.WithGroup("user").Named("db").With("name", "taro").InfoWithContext(ctx, "info message", "id", 123)
+		child.Named("db").WithGroup("user").With("name", "taro").InfoWithContext(ctx, "info message", "id", 123)
+		// the empty groups are dropped
+		child.WithGroup("user").WithGroup("address").InfoWithContext(ctx, "info message")
+		expected := []string{
+			`{"time":"` + runner.TestingTimeStr + `","level":"INFO","msg":"info message","request_id":"req-123",` +
+				`"logger":"http.router","id":123,"project":"test"}`,
+			`{"time":"` + runner.TestingTimeStr + `","level":"WARN","msg":"warn message","request_id":"req-123",` +
+				`"logger":"http.router","project":"test","user":{"name":"taro","id":123}}`,
+			`{"time":"` + runner.TestingTimeStr + `","level":"INFO","msg":"info message","request_id":"req-123",` +
+				`"logger":"http.router.db","project":"test","user":{"name":"taro","id":123}}`,
+			`{"time":"` + runner.TestingTimeStr + `","level":"INFO","msg":"info message","request_id":"req-123",` +
+				`"logger":"http.router.db","project":"test","user":{"name":"taro","id":123}}`,
+			`{"time":"` + runner.TestingTimeStr + `","level":"INFO","msg":"info message","request_id":"req-123",` +
+				`"logger":"http.router","project":"test"}`,
+		}
+		assert.Equal(t, strings.Join(expected, "\n")+"\n", buf.String())
+	})
+
+	buf := bytes.NewBuffer(nil)
+	SetGlobalLogger(buf, WithFormat(formats.Text))
+	defer SetGlobalLogger(os.Stdout)
+	SetLevelOverride("jobs", levels.Warn)
+	defer RemoveLevelOverride("jobs")
+	Named("jobs").Info("info message")
+	Named("jobs").With("job", "cleanup").Warn("warn message")
+	WithGroup("job").Info("info message", "name", "cleanup")
+	With("job", "cleanup").Info("info message")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `msg="warn message" job=cleanup logger=jobs`)
+	assert.Contains(t, lines[1], `msg="info message" job.name=cleanup`)
+	assert.Contains(t, lines[2], `msg="info message" job=cleanup`)
+}
+
+func TestContextLogger(t *testing.T) {
+	global := bytes.NewBuffer(nil)
+	SetGlobalLogger(global, WithFormat(formats.Text))
+	defer SetGlobalLogger(os.Stdout)
+
+	ctx := context.Background()
+	assert.Equal(t, FromContext(ctx), FromContext(context.WithValue(ctx, struct{}{}, "value")))
+	InfoWithContext(nil, "global message")
+
+	scoped := bytes.NewBuffer(nil)
+	ctx = NewContext(ctx, New(scoped, WithFormat(formats.Text)).With("request_id", "req-123"))
+	DebugWithContext(ctx, "debug message")
+	InfoWithContext(ctx, "info message")
+	WarnWithContext(ctx, "warn message")
+	ErrorWithContext(ctx, "error message")
+	FromContext(ctx).Named("db").Info("query message")
+
+	assert.Equal(t, 1, strings.Count(global.String(), "\n"))
+	assert.Contains(t, global.String(), `msg="global message"`)
+	lines := strings.Split(strings.TrimSpace(scoped.String()), "\n")
+	require.Len(t, lines, 5)
+	for _, line := range lines {
+		assert.Contains(t, line, "request_id=req-123")
+	}
+	assert.Contains(t, lines[4], `msg="query message" request_id=req-123 logger=db`)
+}
+
+type tenantKey struct{}
+
+type userKey struct{}
+
+func TestContextExtractors(t *testing.T) {
+	runner.RunTest(t, "context extractors test", func(t *testing.T) {
+		unregister := RegisterContextExtractor(ContextValue[string]("tenant", tenantKey{}))
+		defer unregister()
+		defer RegisterContextExtractor(ContextValue[int]("user_id", userKey{}))()
+
+		ctx := context.WithValue(context.Background(), userKey{}, 123)
+		ctx = context.WithValue(ctx, tenantKey{}, "acme")
+		assert.Equal(t, []slog.Attr{slog.String("tenant", "acme"), slog.Int("user_id", 123)}, ContextAttrs(ctx))
+
+		buf := bytes.NewBuffer(nil)
+		l := New(buf, WithFormat(formats.JSON),
+			WithAttrs(slog.String("env", "DEV"), slog.String("app", "core")),
+			WithContextExtractors(func(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+				return append(attrs, slog.Bool("traced", true))
+			}))
+		for range 3 {
+			l.InfoWithContext(ctx, "info message", "id", 1)
+		}
+		expected := `{"time":"` + runner.TestingTimeStr + `","level":"INFO","msg":"info message","env":"DEV","app":"core",` +
+			`"id":1,"tenant":"acme","user_id":123,"traced":true}` + "\n"
+		assert.Equal(t, strings.Repeat(expected, 3), buf.String())
+
+		unregister()
+		assert.Equal(t, []slog.Attr{slog.Int("user_id", 123)}, ContextAttrs(ctx))
+
+		other := context.WithValue(context.Background(), struct{}{}, "value")
+		assert.Zero(t, testing.AllocsPerRun(100, func() {
+			_ = ContextAttrs(other)
+		}))
+	})
+}
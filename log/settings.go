@@ -1,20 +1,24 @@
 package log
 
 import (
-	"context"
 	"io"
+	"log/slog"
 
 	"github.com/gotech-labs/core/log/formats"
 	"github.com/gotech-labs/core/log/levels"
 )
 
 type settings struct {
-	out                  io.Writer
-	format               formats.Format
-	level                levels.Level
-	withAttrsFunc        func() map[string]any
-	withContextAttrsFunc func(context.Context) map[string]any
-	pretty               bool
+	out               io.Writer
+	format            formats.Format
+	name              string
+	level             *slog.LevelVar
+	stackTraceLevel   levels.Level
+	attrs             []slog.Attr
+	contextExtractors []ContextExtractor
+	pretty            bool
+	color             formats.Color
+	errorExporter     ErrorExporter
 }
 
 type option func(*settings)
@@ -25,20 +29,71 @@ func WithFormat(format formats.Format) func(options *settings) {
 	}
 }
 
+// WithLevel sets the level of the logger, which then no longer follows
+// the shared level changed by SetLevel.
 func WithLevel(level levels.Level) func(options *settings) {
 	return func(settings *settings) {
-		settings.level = level
+		settings.level = &slog.LevelVar{}
+		settings.level.Set(slog.Level(level))
 	}
 }
 
-func WithAttrs(withAttrsFunc func() map[string]any) func(options *settings) {
+// WithLevelVar makes the logger follow the level of v, which can be
+// shared by several loggers and changed at runtime.
+func WithLevelVar(v *slog.LevelVar) func(options *settings) {
 	return func(settings *settings) {
-		settings.withAttrsFunc = withAttrsFunc
+		settings.level = v
 	}
 }
 
-func WithContextAttrs(withContextAttrsFunc func(context.Context) map[string]any) func(options *settings) {
+// WithName names the logger, so that its level can be overridden by
+// SetLevelOverride.
+func WithName(name string) func(options *settings) {
 	return func(settings *settings) {
-		settings.withContextAttrsFunc = withContextAttrsFunc
+		settings.name = name
+	}
+}
+
+// WithAttrs adds attrs to every record, in order.
+func WithAttrs(attrs ...slog.Attr) func(options *settings) {
+	return func(settings *settings) {
+		settings.attrs = append(settings.attrs, attrs...)
+	}
+}
+
+// WithContextExtractors adds the attributes extracted from the context of
+// the records, after the ones of the registered extractors, see
+// RegisterContextExtractor.
+func WithContextExtractors(extractors ...ContextExtractor) func(options *settings) {
+	return func(settings *settings) {
+		settings.contextExtractors = append(settings.contextExtractors, extractors...)
+	}
+}
+
+func WithStackTrace(level levels.Level) func(options *settings) {
+	return func(settings *settings) {
+		settings.stackTraceLevel = level
+	}
+}
+
+// WithPretty tells the console format whether to align the messages and
+// to render the groups and the stack traces on their own lines.
+func WithPretty(pretty bool) func(options *settings) {
+	return func(settings *settings) {
+		settings.pretty = pretty
+	}
+}
+
+// WithColor tells the console format when to use the ANSI colors.
+func WithColor(color formats.Color) func(options *settings) {
+	return func(settings *settings) {
+		settings.color = color
+	}
+}
+
+// WithErrorExporter forwards the errors logged at the ERROR level to exporter.
+func WithErrorExporter(exporter ErrorExporter) func(options *settings) {
+	return func(settings *settings) {
+		settings.errorExporter = exporter
 	}
 }
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package log
+
+// HandleLevelSignals does nothing on the platforms without SIGUSR1 and
+// SIGUSR2.
+func HandleLevelSignals() (stop func()) {
+	return func() {}
+}
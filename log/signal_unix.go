@@ -0,0 +1,45 @@
+//go:build unix
+
+package log
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// levelStep is the distance between the successive standard levels.
+const levelStep = slog.LevelInfo - slog.LevelDebug
+
+// HandleLevelSignals makes SIGUSR1 lower the shared level by one step,
+// down to DEBUG, and SIGUSR2 raise it, up to ERROR. It returns a function
+// which stops handling the signals.
+func HandleLevelSignals() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				step := levelStep
+				if sig == syscall.SIGUSR1 {
+					step = -levelStep
+				}
+				level := min(max(globalLevel.Level()+step, slog.LevelDebug), slog.LevelError)
+				globalLevel.Set(level)
+				Warn("log level changed", "level", level, "signal", sig.String())
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		<-stopped
+	}
+}
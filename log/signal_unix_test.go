@@ -0,0 +1,38 @@
+//go:build unix
+
+package log_test
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/gotech-labs/core/log"
+	"github.com/gotech-labs/core/log/levels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleLevelSignals(t *testing.T) {
+	defer SetLevel(GetLevel())
+	SetGlobalLogger(io.Discard)
+	defer SetGlobalLogger(os.Stdout)
+	SetLevel(levels.Info)
+	stop := HandleLevelSignals()
+	defer stop()
+
+	for _, in := range []struct {
+		signal   syscall.Signal
+		expected levels.Level
+	}{
+		{syscall.SIGUSR1, levels.Debug},
+		{syscall.SIGUSR2, levels.Info},
+		{syscall.SIGUSR2, levels.Warn},
+	} {
+		assert.NoError(t, syscall.Kill(syscall.Getpid(), in.signal))
+		assert.Eventually(t, func() bool {
+			return GetLevel() == in.expected
+		}, time.Second, time.Millisecond, in.signal)
+	}
+}
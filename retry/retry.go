@@ -0,0 +1,103 @@
+// Package retry retries the operations failing with retryable errors.
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gotech-labs/core/errors"
+	"github.com/gotech-labs/core/log"
+)
+
+// ExhaustedError wraps the last error of an operation which could not
+// succeed within the limits of its policy. It defines no status, so the
+// status of the last error applies.
+var ExhaustedError = errors.Namespace("retry").Define("retries exhausted")
+
+// Policy decides how the operations are retried.
+type Policy struct {
+	settings *settings
+}
+
+func NewPolicy(options ...option) *Policy {
+	return &Policy{settings: newSettings(options)}
+}
+
+// Do calls fn until it succeeds, fails with an error which is not
+// retryable, or the limits of the policy are reached, in which case the
+// last error is wrapped in an ExhaustedError. The context is checked
+// between the attempts.
+func Do(ctx context.Context, fn func(ctx context.Context) error, options ...option) error {
+	return NewPolicy(options...).Do(ctx, fn)
+}
+
+func (p *Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	settings := p.settings
+	start := settings.clock.Now()
+	delay := settings.initialDelay
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				p.info(ctx, "attempt succeeded", "attempt", attempt)
+			}
+			return nil
+		}
+		if !settings.retryable(err) {
+			p.warn(ctx, "attempt failed", "attempt", attempt, "retryable", false, "error", err)
+			return err
+		}
+		wait := p.jitter(delay)
+		elapsed := settings.clock.Now().Sub(start)
+		switch {
+		case settings.maxAttempts > 0 && attempt >= settings.maxAttempts:
+			p.warn(ctx, "attempt failed", "attempt", attempt, "retryable", true, "error", err)
+			return ExhaustedError.Wrap(err, "gave up after %d attempts", attempt, "elapsed", elapsed)
+		case settings.maxElapsed > 0 && elapsed+wait > settings.maxElapsed:
+			p.warn(ctx, "attempt failed", "attempt", attempt, "retryable", true, "error", err)
+			return ExhaustedError.Wrap(err, "gave up after %d attempts", attempt, "elapsed", elapsed)
+		}
+		p.warn(ctx, "attempt failed", "attempt", attempt, "retryable", true, "delay", wait, "error", err)
+		if ctxErr := p.wait(ctx, wait); ctxErr != nil {
+			return ExhaustedError.Wrap(errors.Join(ctxErr, err), "canceled after %d attempts", attempt)
+		}
+		delay = min(time.Duration(float64(delay)*settings.multiplier), settings.maxDelay)
+	}
+}
+
+// wait waits for d unless ctx is done first.
+func (p *Policy) wait(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.settings.clock.After(d):
+		return nil
+	}
+}
+
+func (p *Policy) jitter(delay time.Duration) time.Duration {
+	if p.settings.jitter <= 0 {
+		return delay
+	}
+	return time.Duration(float64(delay) * (1 + p.settings.jitter*(2*rand.Float64()-1)))
+}
+
+func (p *Policy) info(ctx context.Context, msg string, args ...any) {
+	if p.settings.logger == nil {
+		log.InfoWithContext(ctx, msg, args...)
+		return
+	}
+	p.settings.logger.InfoWithContext(ctx, msg, args...)
+}
+
+func (p *Policy) warn(ctx context.Context, msg string, args ...any) {
+	if p.settings.logger == nil {
+		log.WarnWithContext(ctx, msg, args...)
+		return
+	}
+	p.settings.logger.WarnWithContext(ctx, msg, args...)
+}
@@ -0,0 +1,143 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotech-labs/core/errors"
+	"github.com/gotech-labs/core/log"
+	"github.com/gotech-labs/core/log/formats"
+	"github.com/gotech-labs/core/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var TemporaryError = errors.Define("temporary db error", errors.WithRetryable(true))
+
+func TestDo(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, in := range []struct {
+		name             string
+		options          []option
+		errs             []error
+		expectedErr      string
+		expectedAttempts int
+		expectedElapsed  time.Duration
+	}{
+		{
+			name:             "test - success",
+			errs:             []error{nil},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "test - success after retries",
+			errs:             []error{TemporaryError.New("deadlock"), TemporaryError.New("deadlock"), nil},
+			expectedAttempts: 3,
+			expectedElapsed:  300 * time.Millisecond,
+		},
+		{
+			name:             "test - not retryable",
+			errs:             []error{errors.ValidationError.New("bad query")},
+			expectedErr:      "bad query",
+			expectedAttempts: 1,
+		},
+		{
+			name:             "test - max attempts",
+			errs:             []error{TemporaryError.New("deadlock"), TemporaryError.New("deadlock"), TemporaryError.New("lock timeout")},
+			expectedErr:      "gave up after 3 attempts",
+			expectedAttempts: 3,
+			expectedElapsed:  300 * time.Millisecond,
+		},
+		{
+			name: "test - max elapsed",
+			options: []option{
+				WithMaxAttempts(0),
+				WithBackoff(time.Second, 4*time.Second, 3),
+				WithMaxElapsed(5 * time.Second),
+			},
+			errs:             []error{context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded},
+			expectedErr:      "gave up after 3 attempts",
+			expectedAttempts: 3,
+			expectedElapsed:  4 * time.Second,
+		},
+	} {
+		t.Run(in.name, func(t *testing.T) {
+			clock := system.NewFakeClock(start)
+			options := append([]option{
+				WithBackoff(100*time.Millisecond, time.Second, 2),
+				WithJitter(0),
+				WithClock(clock),
+				WithLogger(log.New(&bytes.Buffer{})),
+			}, in.options...)
+			attempts := 0
+			err := Do(context.Background(), func(context.Context) error {
+				attempts++
+				return in.errs[attempts-1]
+			}, options...)
+			if in.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Equal(t, in.expectedErr, errors.AsError(err).Message())
+				assert.Equal(t, in.errs[attempts-1], errors.Root(err))
+			}
+			assert.Equal(t, in.expectedAttempts, attempts)
+			assert.Equal(t, in.expectedElapsed, clock.Now().Sub(start))
+		})
+	}
+}
+
+func TestDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cause := TemporaryError.New("deadlock")
+	err := Do(ctx, func(context.Context) error {
+		cancel()
+		return cause
+	}, WithClock(system.NewFakeClock(time.Now())), WithLogger(log.New(&bytes.Buffer{})))
+	assert.True(t, ExhaustedError.Is(err))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, cause)
+	assert.False(t, errors.IsRetryable(err))
+}
+
+func TestDoJitter(t *testing.T) {
+	clock := system.NewFakeClock(time.Now())
+	start := clock.Now()
+	_ = NewPolicy(
+		WithMaxAttempts(2),
+		WithBackoff(time.Second, time.Second, 1),
+		WithJitter(0.5),
+		WithClock(clock),
+		WithLogger(log.New(&bytes.Buffer{})),
+	).Do(context.Background(), func(context.Context) error {
+		return TemporaryError.New("deadlock")
+	})
+	elapsed := clock.Now().Sub(start)
+	assert.GreaterOrEqual(t, elapsed, 500*time.Millisecond)
+	assert.LessOrEqual(t, elapsed, 1500*time.Millisecond)
+}
+
+func TestDoLog(t *testing.T) {
+	var buf bytes.Buffer
+	attempts := 0
+	err := Do(context.Background(), func(context.Context) error {
+		if attempts++; attempts < 2 {
+			return stderrors.New("connection reset")
+		}
+		return nil
+	},
+		WithClassifier(func(error) bool { return true }),
+		WithBackoff(time.Second, time.Second, 1),
+		WithJitter(0),
+		WithClock(system.NewFakeClock(time.Now())),
+		WithLogger(log.New(&buf, log.WithFormat(formats.Text))))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `level=WARN msg="attempt failed" attempt=1 retryable=true delay=1s error="connection reset"`)
+	assert.Contains(t, lines[1], `level=INFO msg="attempt succeeded" attempt=2`)
+}
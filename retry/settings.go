@@ -0,0 +1,93 @@
+package retry
+
+import (
+	"time"
+
+	"github.com/gotech-labs/core/errors"
+	"github.com/gotech-labs/core/log"
+	"github.com/gotech-labs/core/system"
+)
+
+type settings struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	multiplier   float64
+	jitter       float64
+	maxElapsed   time.Duration
+	retryable    func(error) bool
+	clock        system.Clock
+	logger       log.Logger
+}
+
+type option func(*settings)
+
+// WithMaxAttempts limits the number of attempts, 0 meaning no limit.
+func WithMaxAttempts(attempts int) option {
+	return func(settings *settings) {
+		settings.maxAttempts = attempts
+	}
+}
+
+// WithBackoff sets the exponential backoff: the first retry waits for
+// initial, then every delay is multiplied by multiplier up to max.
+func WithBackoff(initial, max time.Duration, multiplier float64) option {
+	return func(settings *settings) {
+		settings.initialDelay = initial
+		settings.maxDelay = max
+		settings.multiplier = multiplier
+	}
+}
+
+// WithJitter randomizes the delays by up to the given fraction, like 0.2
+// for ±20%, so that the clients failing together don't retry together.
+func WithJitter(fraction float64) option {
+	return func(settings *settings) {
+		settings.jitter = fraction
+	}
+}
+
+// WithMaxElapsed stops retrying when the next attempt would start after
+// d has elapsed since the first one, 0 meaning no limit.
+func WithMaxElapsed(d time.Duration) option {
+	return func(settings *settings) {
+		settings.maxElapsed = d
+	}
+}
+
+// WithClassifier replaces errors.IsRetryable to decide which errors are
+// retried.
+func WithClassifier(retryable func(error) bool) option {
+	return func(settings *settings) {
+		settings.retryable = retryable
+	}
+}
+
+func WithClock(clock system.Clock) option {
+	return func(settings *settings) {
+		settings.clock = clock
+	}
+}
+
+// WithLogger logs the attempts with logger instead of the global logger.
+func WithLogger(logger log.Logger) option {
+	return func(settings *settings) {
+		settings.logger = logger
+	}
+}
+
+func newSettings(options []option) *settings {
+	settings := &settings{
+		maxAttempts:  3,
+		initialDelay: 100 * time.Millisecond,
+		maxDelay:     10 * time.Second,
+		multiplier:   2,
+		jitter:       0.2,
+		retryable:    errors.IsRetryable,
+		clock:        system.DefaultClock,
+	}
+	for _, option := range options {
+		option(settings)
+	}
+	return settings
+}
@@ -0,0 +1,59 @@
+package system
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time and waits, so that the code depending on
+// time can be tested deterministically.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// DefaultClock is the clock of the server, based on CurrentTime.
+var DefaultClock Clock = serverClock{}
+
+type serverClock struct{}
+
+func (serverClock) Now() time.Time {
+	return CurrentTime()
+}
+
+func (serverClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// FakeClock is a deterministic clock for tests. Its time only moves when
+// it is advanced or waited on, and waiting returns immediately.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After advances the clock by d and returns a channel which has already
+// received the new time.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Advance(d)
+	return ch
+}
+
+// Advance moves the clock forward by d and returns the new time.
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}